@@ -0,0 +1,103 @@
+package gohttptest
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Причины остановки теста, BenchmarkResult.AbortReason
+const (
+	StopRequestCount = "request count reached"
+	StopDuration     = "duration elapsed"
+	StopInterrupt    = "interrupted"
+	StopFailureRate  = "failure rate threshold exceeded"
+	StopContext      = "context canceled"
+)
+
+// Останавливает тест и запоминает первую причину остановки
+type stopper struct {
+	mu     sync.Mutex
+	reason string
+	cancel context.CancelFunc
+}
+
+func (s *stopper) stop(reason string) {
+	s.mu.Lock()
+	if s.reason == "" {
+		s.reason = reason
+	}
+	s.mu.Unlock()
+	s.cancel()
+}
+
+func (s *stopper) stopReason() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.reason
+}
+
+// Окно, за которое считается доля ошибок для WithMaxFailureRate
+const failureWindow = 10 * time.Second
+
+// Раз в секунду проверяет долю ошибок за последние failureWindow
+// и останавливает тест, если она больше rate
+type failureGuard struct {
+	st    *stats
+	rate  float64
+	abort func()
+	stop  chan struct{}
+	wg    sync.WaitGroup
+}
+
+func startFailureGuard(st *stats, rate float64, abort func()) *failureGuard {
+	g := &failureGuard{
+		st:    st,
+		rate:  rate,
+		abort: abort,
+		stop:  make(chan struct{}),
+	}
+	g.wg.Add(1)
+	go g.run()
+	return g
+}
+
+func (g *failureGuard) run() {
+	defer g.wg.Done()
+
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+
+	// Снимки счетчиков за последние секунды, первый - начало окна
+	window := []liveSnapshot{{}}
+	for {
+		select {
+		case <-g.stop:
+			return
+		case <-ticker.C:
+			snap := liveSnapshot{
+				requests: g.st.liveRequests.Load(),
+				failed:   g.st.liveFailed.Load(),
+			}
+			window = append(window, snap)
+			if len(window) > int(failureWindow/time.Second)+1 {
+				window = window[1:]
+			}
+
+			first := window[0]
+			requests := snap.requests - first.requests
+			if requests == 0 {
+				continue
+			}
+			if float64(snap.failed-first.failed)/float64(requests) > g.rate {
+				g.abort()
+				return
+			}
+		}
+	}
+}
+
+func (g *failureGuard) close() {
+	close(g.stop)
+	g.wg.Wait()
+}
@@ -0,0 +1,99 @@
+package gohttptest
+
+import (
+	"bufio"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// Формат журнала доступа для WithAccessLog
+type AccessLogFormat int
+
+const (
+	// Apache LogFormat "combined":
+	// %h %l %u %t "%r" %>s %b "%{Referer}i" "%{User-agent}i"
+	AccessLogApacheCombined AccessLogFormat = iota
+	// Формат nginx по умолчанию (log_format combined):
+	// $remote_addr - $remote_user [$time_local] "$request" $status
+	// $body_bytes_sent "$http_referer" "$http_user_agent"
+	AccessLogNginxDefault
+)
+
+// Строки журнала: запрос, статус и размер ответа. Кавычки внутри полей
+// экранируются обратной косой чертой у Apache и \x22 у nginx
+var accessLogPatterns = map[AccessLogFormat]*regexp.Regexp{
+	AccessLogApacheCombined: regexp.MustCompile(`^\S+ \S+ \S+ \[[^\]]+\] "((?:[^"\\]|\\.)*)" (\d{3}) (?:\d+|-) "(?:[^"\\]|\\.)*" "(?:[^"\\]|\\.)*"`),
+	AccessLogNginxDefault:   regexp.MustCompile(`^\S+ - \S+ \[[^\]]+\] "((?:[^"\\]|\\.)*)" (\d{3}) \d+ "(?:[^"\\]|\\.)*" "(?:[^"\\]|\\.)*"`),
+}
+
+// Читает запросы из журнала доступа, файлы .gz распаковываются.
+// Адреса - пути относительно site, вес - количество одинаковых запросов.
+// Строка, не соответствующая формату, - ошибка с номером строки
+func readAccessLog(path string, format AccessLogFormat) ([]WeightedURL, error) {
+	re, ok := accessLogPatterns[format]
+	if !ok {
+		return nil, fmt.Errorf("unknown access log format %d", format)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var r io.Reader = f
+	if strings.HasSuffix(path, ".gz") {
+		gz, err := gzip.NewReader(f)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		defer gz.Close()
+		r = gz
+	}
+
+	var entries []WeightedURL
+	index := make(map[string]int)
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 64*1024), 1024*1024)
+	for line := 1; sc.Scan(); line++ {
+		text := sc.Text()
+		if strings.TrimSpace(text) == "" {
+			continue
+		}
+		m := re.FindStringSubmatch(text)
+		if m == nil {
+			return nil, fmt.Errorf("%s:%d: line does not match access log format", path, line)
+		}
+		// Статус проверяется только форматом, повторяются все запросы.
+		// Запрос "-" или мусор вместо строки запроса (ответы 400) пропускаются
+		method, rest, ok := strings.Cut(m[1], " ")
+		if !ok {
+			continue
+		}
+		target, _, _ := strings.Cut(rest, " ")
+		if !strings.HasPrefix(target, "/") {
+			continue
+		}
+		if _, err := normalizeMethod(method); err != nil {
+			continue
+		}
+
+		key := method + " " + target
+		if i, ok := index[key]; ok {
+			entries[i].Weight++
+			continue
+		}
+		index[key] = len(entries)
+		entries = append(entries, WeightedURL{URL: target, Weight: 1, Method: method})
+	}
+	if err := sc.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if len(entries) == 0 {
+		return nil, fmt.Errorf("%s contains no requests", path)
+	}
+	return entries, nil
+}
@@ -0,0 +1,38 @@
+package gohttptest
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+)
+
+// Формат файла SaveBaseline
+type baselineFile struct {
+	SavedAt time.Time       `json:"savedAt"`
+	Result  BenchmarkResult `json:"result"`
+}
+
+// Сохраняет result в JSON файл как эталон для последующих Compare.
+// Вместе с результатом записывается время сохранения savedAt
+func SaveBaseline(result BenchmarkResult, path string) error {
+	data, err := json.MarshalIndent(baselineFile{SavedAt: time.Now(), Result: result}, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
+
+// Читает эталон, сохраненный SaveBaseline. Если файла нет, ошибка
+// удовлетворяет errors.Is(err, fs.ErrNotExist) - например, первый запуск в CI
+func LoadBaseline(path string) (BenchmarkResult, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return BenchmarkResult{}, err
+	}
+	var f baselineFile
+	if err := json.Unmarshal(data, &f); err != nil {
+		return BenchmarkResult{}, fmt.Errorf("parse baseline %s: %w", path, err)
+	}
+	return f.Result, nil
+}
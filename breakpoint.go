@@ -0,0 +1,61 @@
+package gohttptest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// Увеличивает конкурентность шагами WithBreakpoint, пока доля ошибок
+// failedCount/totalRequests не превысит порог. Первый шаг - Concurrency из
+// опций или размер шага. Каждый шаг длится stepDuration. Возвращает
+// результаты всех шагов и индекс шага, на котором порог превышен, или -1,
+// если он не был превышен до предельной конкурентности
+func RunBreakpoint(ctx context.Context, site string, opts ...Option) ([]BenchmarkResult, int, error) {
+	base, err := NewConfig(opts...)
+	if err != nil {
+		return nil, -1, err
+	}
+	if base.Context == nil {
+		base.Context = ctx
+	}
+	// Шаг ограничен временем, а не количеством запросов
+	base.Duration = base.BreakpointStepDuration
+	base.Requests = 0
+	concurrency := base.Concurrency
+	if concurrency <= 0 {
+		concurrency = base.BreakpointStep
+	}
+
+	var steps []BenchmarkResult
+	for ; concurrency <= maxSearchConcurrency; concurrency += base.BreakpointStep {
+		cfg := *base
+		cfg.Concurrency = concurrency
+		cfg.Name = fmt.Sprintf("concurrency %d", concurrency)
+		cfg.Output = io.Discard
+
+		r, err := run(cfg, site)
+		if r.TotalRequests == 0 && err != nil {
+			return steps, -1, err
+		}
+		if r.AbortReason == StopInterrupt || r.AbortReason == StopContext {
+			return steps, -1, errors.New("breakpoint test interrupted")
+		}
+		steps = append(steps, r)
+
+		crossed := r.TotalRequests == 0 ||
+			float64(r.FailedCount)/float64(r.TotalRequests) > base.BreakpointThreshold
+		if !base.Quiet {
+			printStep(base.Output, concurrency, r, !crossed)
+		}
+		if crossed {
+			if !base.Quiet {
+				fmt.Fprintf(base.Output, "Breakpoint: concurrency %d, failure rate above %.1f%%\n",
+					concurrency, base.BreakpointThreshold*100)
+			}
+			return steps, len(steps) - 1, nil
+		}
+	}
+	return steps, -1, nil
+}
@@ -0,0 +1,85 @@
+package gohttptest
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Как часто WithCheckpointFile сохраняет статистику: по времени или по
+// количеству запросов с прошлого сохранения, что наступит раньше
+const (
+	checkpointInterval = 60 * time.Second
+	checkpointRequests = 10000
+)
+
+// Периодическое сохранение статистики в файл контрольной точки
+type checkpoint struct {
+	path string
+	// Запросы, учтенные в контрольной точке до этого запуска
+	resumed  int
+	last     time.Time
+	lastSeen int
+}
+
+// Читает контрольную точку, если файл есть. Возвращает количество уже
+// учтенных запросов, 0 - файла нет
+func readCheckpoint(path string) (int, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	var saved struct {
+		TotalRequests   int `json:"totalRequests"`
+		ResumedRequests int `json:"resumedRequests"`
+	}
+	if err := json.Unmarshal(data, &saved); err != nil {
+		return 0, fmt.Errorf("parse %s: %w", path, err)
+	}
+	return saved.TotalRequests + saved.ResumedRequests, nil
+}
+
+func newCheckpoint(path string, resumed int) *checkpoint {
+	return &checkpoint{path: path, resumed: resumed, last: time.Now()}
+}
+
+// Нужно ли сохранить статистику после total запросов этого запуска
+func (c *checkpoint) due(total int) bool {
+	return total-c.lastSeen >= checkpointRequests || time.Since(c.last) >= checkpointInterval
+}
+
+// Записывает снимок r атомарно: через временный файл и переименование,
+// чтобы прерывание во время записи не испортило контрольную точку
+func (c *checkpoint) save(r BenchmarkResult) error {
+	c.last, c.lastSeen = time.Now(), r.TotalRequests
+	r.ResumedRequests = c.resumed
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return err
+	}
+	f, err := os.CreateTemp(filepath.Dir(c.path), "."+filepath.Base(c.path)+".*")
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+	if err := os.Rename(f.Name(), c.path); err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+	return nil
+}
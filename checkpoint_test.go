@@ -0,0 +1,66 @@
+package gohttptest
+
+import (
+	"bytes"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeCheckpointFile(t *testing.T, done int) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "ckpt.json")
+	c := newCheckpoint(path, 0)
+	if err := c.save(BenchmarkResult{TotalRequests: done}); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// Продолженный тест отправляет только оставшиеся запросы и удаляет файл
+func TestCheckpointResume(t *testing.T) {
+	path := writeCheckpointFile(t, 30)
+	srv := NewMockServer(nil)
+	defer srv.Close()
+
+	res, err := Test(srv.URL(), 2, 50, WithCheckpointFile(path), WithQuiet(true))
+	if err != nil {
+		t.Fatalf("Test: %v", err)
+	}
+	if res.ResumedRequests != 30 || res.TotalRequests != 20 {
+		t.Errorf("ResumedRequests = %d, TotalRequests = %d, want 30 and 20", res.ResumedRequests, res.TotalRequests)
+	}
+	if got := srv.RequestCount(); got != 20 {
+		t.Errorf("server got %d requests, want 20", got)
+	}
+	if _, err := os.Stat(path); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("checkpoint file not removed: %v", err)
+	}
+}
+
+// Контрольная точка уже покрывает все запросы: ничего не отправляется,
+// файл удаляется без предупреждений
+func TestCheckpointAlreadyComplete(t *testing.T) {
+	path := writeCheckpointFile(t, 50)
+	srv := NewMockServer(nil)
+	defer srv.Close()
+
+	var out bytes.Buffer
+	res, err := Test(srv.URL(), 2, 50, WithCheckpointFile(path),
+		WithOutput(&out), WithErrorOutput(&out), WithProgress(false))
+	if err != nil {
+		t.Fatalf("Test: %v", err)
+	}
+	if res.ResumedRequests != 50 || res.TotalRequests != 0 || srv.RequestCount() != 0 {
+		t.Errorf("ResumedRequests = %d, TotalRequests = %d, server requests = %d",
+			res.ResumedRequests, res.TotalRequests, srv.RequestCount())
+	}
+	if _, err := os.Stat(path); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("checkpoint file not removed: %v", err)
+	}
+	if bytes.Contains(out.Bytes(), []byte("Warning")) {
+		t.Errorf("unexpected warning:\n%s", out.String())
+	}
+}
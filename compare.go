@@ -0,0 +1,123 @@
+package gohttptest
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+)
+
+// Порог регрессии в процентах для метрик без WithRegressionThreshold
+const defaultRegressionThreshold = 10.0
+
+// Сравниваемая метрика BenchmarkResult, имя совпадает с полем JSON
+type comparedMetric struct {
+	name         string
+	value        func(r BenchmarkResult) float64
+	higherBetter bool
+	duration     bool
+}
+
+func durationMetric(name string, get func(r BenchmarkResult) time.Duration) comparedMetric {
+	return comparedMetric{name: name, value: func(r BenchmarkResult) float64 { return float64(get(r)) }, duration: true}
+}
+
+var comparedMetrics = []comparedMetric{
+	{name: "requestsPerSecond", value: func(r BenchmarkResult) float64 { return r.RequestsPerSecond }, higherBetter: true},
+	{name: "successRate", value: func(r BenchmarkResult) float64 { return r.SuccessRate }, higherBetter: true},
+	{name: "apdexScore", value: func(r BenchmarkResult) float64 { return r.ApdexScore }, higherBetter: true},
+	durationMetric("avgDuration", func(r BenchmarkResult) time.Duration { return r.AvgDuration }),
+	durationMetric("p50", func(r BenchmarkResult) time.Duration { return r.P50 }),
+	durationMetric("p90", func(r BenchmarkResult) time.Duration { return r.P90 }),
+	durationMetric("p95", func(r BenchmarkResult) time.Duration { return r.P95 }),
+	durationMetric("p99", func(r BenchmarkResult) time.Duration { return r.P99 }),
+	durationMetric("p999", func(r BenchmarkResult) time.Duration { return r.P999 }),
+	durationMetric("ttfbP99", func(r BenchmarkResult) time.Duration { return r.TTFBP99 }),
+}
+
+// Изменение одной метрики
+type MetricComparison struct {
+	Metric string `json:"metric"`
+	// Значения, длительности - в наносекундах
+	Baseline float64 `json:"baseline"`
+	Current  float64 `json:"current"`
+	// Изменение в процентах от Baseline, 0 при нулевом Baseline
+	DeltaPct float64 `json:"deltaPct"`
+	// Ухудшение больше Threshold процентов
+	Threshold  float64 `json:"threshold"`
+	Regression bool    `json:"regression"`
+
+	duration bool
+}
+
+// Результат Compare
+type ComparisonReport struct {
+	Metrics []MetricComparison `json:"metrics"`
+	// Есть хотя бы одна регрессия
+	Regression bool `json:"regression"`
+}
+
+// Сравнивает current с baseline по RPS, доле успешных запросов, Apdex и
+// перцентилям. Регрессия - ухудшение метрики больше порога
+// WithRegressionThreshold, по умолчанию 10%. Другие опции не учитываются
+func Compare(baseline, current BenchmarkResult, opts ...Option) (ComparisonReport, error) {
+	cfg, err := NewConfig(opts...)
+	if err != nil {
+		return ComparisonReport{}, err
+	}
+	var report ComparisonReport
+	for _, m := range comparedMetrics {
+		c := MetricComparison{
+			Metric:    m.name,
+			Baseline:  m.value(baseline),
+			Current:   m.value(current),
+			Threshold: defaultRegressionThreshold,
+			duration:  m.duration,
+		}
+		if t, ok := cfg.RegressionThresholds[m.name]; ok {
+			c.Threshold = t
+		}
+		if c.Baseline != 0 {
+			c.DeltaPct = (c.Current - c.Baseline) / c.Baseline * 100
+		}
+		worse := c.DeltaPct
+		if m.higherBetter {
+			worse = -worse
+		}
+		c.Regression = worse > c.Threshold
+		report.Regression = report.Regression || c.Regression
+		report.Metrics = append(report.Metrics, c)
+	}
+	return report, nil
+}
+
+// Пишет сравнение в формате FormatText или FormatJSON
+func (r ComparisonReport) Write(w io.Writer, format string) error {
+	if format == FormatJSON {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(r)
+	}
+	fmt.Fprintln(w, "COMPARISON")
+	fmt.Fprintf(w, "%-18s  %12s  %12s  %8s\n", "Metric", "Baseline", "Current", "Delta")
+	for _, m := range r.Metrics {
+		line := fmt.Sprintf("%-18s  %12s  %12s  %+7.1f%%", m.Metric, m.format(m.Baseline), m.format(m.Current), m.DeltaPct)
+		if m.Regression {
+			line += fmt.Sprintf("  REGRESSION (threshold %.1f%%)", m.Threshold)
+		}
+		fmt.Fprintln(w, line)
+	}
+	if r.Regression {
+		fmt.Fprintln(w, "Result: regression detected")
+	} else {
+		fmt.Fprintln(w, "Result: no regressions")
+	}
+	return nil
+}
+
+func (m MetricComparison) format(v float64) string {
+	if m.duration {
+		return time.Duration(v).Round(time.Microsecond).String()
+	}
+	return fmt.Sprintf("%.2f", v)
+}
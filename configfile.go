@@ -0,0 +1,677 @@
+package gohttptest
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"os"
+	"slices"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Схема YAML файла конфигурации. Ключи совпадают с именами опций With*
+// в camelCase, опции с несколькими аргументами записываются вложенными
+// объектами
+type fileConfig struct {
+	Name            string            `yaml:"name,omitempty"`
+	Method          string            `yaml:"method,omitempty"`
+	Timeout         time.Duration     `yaml:"timeout,omitempty"`
+	DialTimeout     time.Duration     `yaml:"dialTimeout,omitempty"`
+	ResponseTimeout time.Duration     `yaml:"responseTimeout,omitempty"`
+	Concurrency     int               `yaml:"concurrency,omitempty"`
+	RequestCount    int               `yaml:"requestCount,omitempty"`
+	Duration        time.Duration     `yaml:"duration,omitempty"`
+	RateLimit       float64           `yaml:"rateLimit,omitempty"`
+	Headers         map[string]string `yaml:"headers,omitempty"`
+	Verbose         bool              `yaml:"verbose,omitempty"`
+	Quiet           bool              `yaml:"quiet,omitempty"`
+
+	Body             *fileBody `yaml:"body,omitempty"`
+	BodyFile         string    `yaml:"bodyFile,omitempty"`
+	BodyFileStreamed string    `yaml:"bodyFileStreamed,omitempty"`
+	JSONSchemaBody   string    `yaml:"jsonSchemaBody,omitempty"`
+	CompressBody     bool      `yaml:"compressBody,omitempty"`
+	AcceptEncoding   string    `yaml:"acceptEncoding,omitempty"`
+
+	OutputFormat   string              `yaml:"outputFormat,omitempty"`
+	CSVLog         string              `yaml:"csvLog,omitempty"`
+	InfluxDBOutput *fileInfluxDBOutput `yaml:"influxDBOutput,omitempty"`
+	GatlingLog     string              `yaml:"gatlingLog,omitempty"`
+	// Указатель: по умолчанию прогресс включен
+	Progress    *bool  `yaml:"progress,omitempty"`
+	JUnitOutput string `yaml:"junitOutput,omitempty"`
+	Checkpoint  string `yaml:"checkpointFile,omitempty"`
+	Histogram   bool   `yaml:"histogram,omitempty"`
+	Preview     bool   `yaml:"preview,omitempty"`
+	DryRun      bool   `yaml:"dryRun,omitempty"`
+
+	CoordinatedOmissionCorrection bool           `yaml:"coordinatedOmissionCorrection,omitempty"`
+	Warmup                        time.Duration  `yaml:"warmup,omitempty"`
+	WarmupRequests                int            `yaml:"warmupRequests,omitempty"`
+	RampUp                        time.Duration  `yaml:"rampUp,omitempty"`
+	RampSteps                     int            `yaml:"rampSteps,omitempty"`
+	ThinkTime                     *fileThinkTime `yaml:"thinkTime,omitempty"`
+	PoissonThinkTime              time.Duration  `yaml:"poissonThinkTime,omitempty"`
+	Prewarm                       int            `yaml:"prewarm,omitempty"`
+	Retry                         *fileRetry     `yaml:"retry,omitempty"`
+
+	TLSSkipVerify bool            `yaml:"tlsSkipVerify,omitempty"`
+	TLSCACert     string          `yaml:"tlsCACert,omitempty"`
+	ClientCert    *fileClientCert `yaml:"clientCert,omitempty"`
+	SNI           string          `yaml:"sni,omitempty"`
+	HTTP2         *bool           `yaml:"http2,omitempty"`
+
+	Proxy       string           `yaml:"proxy,omitempty"`
+	SOCKS5Proxy *fileSOCKS5Proxy `yaml:"socks5Proxy,omitempty"`
+	IPv4Only    bool             `yaml:"ipv4Only,omitempty"`
+	IPv6Only    bool             `yaml:"ipv6Only,omitempty"`
+
+	DisableKeepAlive    bool `yaml:"disableKeepAlive,omitempty"`
+	MaxIdleConns        int  `yaml:"maxIdleConns,omitempty"`
+	MaxIdleConnsPerHost int  `yaml:"maxIdleConnsPerHost,omitempty"`
+	MaxConnsPerHost     int  `yaml:"maxConnsPerHost,omitempty"`
+	// Указатель: 0 отключает редиректы, без значения - 10
+	MaxRedirects *int `yaml:"maxRedirects,omitempty"`
+
+	BasicAuth         *fileBasicAuth      `yaml:"basicAuth,omitempty"`
+	BearerToken       string              `yaml:"bearerToken,omitempty"`
+	APIKey            *fileAPIKey         `yaml:"apiKey,omitempty"`
+	CookieJar         bool                `yaml:"cookieJar,omitempty"`
+	Cookies           map[string]string   `yaml:"cookies,omitempty"`
+	UserAgent         string              `yaml:"userAgent,omitempty"`
+	UserAgentRotation []string            `yaml:"userAgentRotation,omitempty"`
+	HostHeader        string              `yaml:"hostHeader,omitempty"`
+	RequestIDHeader   string              `yaml:"requestIDHeader,omitempty"`
+	CacheBust         bool                `yaml:"cacheBust,omitempty"`
+	RandomQueryParams map[string][]string `yaml:"randomQueryParams,omitempty"`
+
+	URLs         []string          `yaml:"urls,omitempty"`
+	URLFile      string            `yaml:"urlFile,omitempty"`
+	HARFile      string            `yaml:"harFile,omitempty"`
+	HARThinkTime bool              `yaml:"harThinkTime,omitempty"`
+	AccessLog    *fileAccessLog    `yaml:"accessLog,omitempty"`
+	OpenAPISpec  *fileOpenAPISpec  `yaml:"openAPISpec,omitempty"`
+	WeightedURLs []fileWeightedURL `yaml:"weightedURLs,omitempty"`
+	URLTemplate  *fileURLTemplate  `yaml:"urlTemplate,omitempty"`
+
+	ExpectedStatus        []int             `yaml:"expectedStatus,omitempty"`
+	RequiredHeaders       map[string]string `yaml:"requiredHeaders,omitempty"`
+	BodyRegex             string            `yaml:"bodyRegex,omitempty"`
+	ValidateContentLength bool              `yaml:"validateContentLength,omitempty"`
+	SLAAssertions         []fileSLA         `yaml:"slaAssertions,omitempty"`
+	MaxFailureRate        float64           `yaml:"maxFailureRate,omitempty"`
+	ApdexThreshold        time.Duration     `yaml:"apdexThreshold,omitempty"`
+
+	HealthCheck          *fileHealthCheck          `yaml:"healthCheck,omitempty"`
+	SaturationThresholds *fileSaturationThresholds `yaml:"saturationThresholds,omitempty"`
+	Breakpoint           *fileBreakpoint           `yaml:"breakpoint,omitempty"`
+	RegressionThresholds map[string]float64        `yaml:"regressionThresholds,omitempty"`
+
+	PrometheusEndpoint      string       `yaml:"prometheusEndpoint,omitempty"`
+	PrometheusHistogramFile string       `yaml:"prometheusHistogramFile,omitempty"`
+	StatsD                  *fileStatsD  `yaml:"statsD,omitempty"`
+	ResourceTracking        bool         `yaml:"resourceTracking,omitempty"`
+	CPUProfile              string       `yaml:"cpuProfile,omitempty"`
+	HeapProfile             string       `yaml:"heapProfile,omitempty"`
+	SlackWebhook            *fileSlack   `yaml:"slackWebhook,omitempty"`
+	Webhook                 *fileWebhook `yaml:"webhook,omitempty"`
+}
+
+type fileBody struct {
+	Content     string `yaml:"content"`
+	ContentType string `yaml:"contentType,omitempty"`
+}
+
+type fileThinkTime struct {
+	Min time.Duration `yaml:"min"`
+	Max time.Duration `yaml:"max"`
+}
+
+type fileRetry struct {
+	MaxRetries int           `yaml:"maxRetries"`
+	Backoff    time.Duration `yaml:"backoff,omitempty"`
+}
+
+type fileClientCert struct {
+	Cert string `yaml:"cert"`
+	Key  string `yaml:"key"`
+}
+
+type fileSOCKS5Proxy struct {
+	Addr     string `yaml:"addr"`
+	Username string `yaml:"username,omitempty"`
+	Password string `yaml:"password,omitempty"`
+}
+
+type fileBasicAuth struct {
+	Username string `yaml:"username"`
+	Password string `yaml:"password"`
+}
+
+type fileAPIKey struct {
+	Header string `yaml:"header"`
+	Key    string `yaml:"key"`
+}
+
+// Формат: "apacheCombined" или "nginxDefault"
+type fileAccessLog struct {
+	Path   string `yaml:"path"`
+	Format string `yaml:"format"`
+}
+
+// Имена форматов журнала доступа в YAML
+var accessLogFormatNames = map[string]AccessLogFormat{
+	"apacheCombined": AccessLogApacheCombined,
+	"nginxDefault":   AccessLogNginxDefault,
+}
+
+type fileOpenAPISpec struct {
+	Path        string   `yaml:"path"`
+	IncludeTags []string `yaml:"includeTags,omitempty"`
+	ExcludeTags []string `yaml:"excludeTags,omitempty"`
+	IncludePath string   `yaml:"includePath,omitempty"`
+	ExcludePath string   `yaml:"excludePath,omitempty"`
+}
+
+type fileURLTemplate struct {
+	Template string `yaml:"template"`
+	DataFile string `yaml:"dataFile"`
+}
+
+type fileWeightedURL struct {
+	URL     string            `yaml:"url"`
+	Weight  int               `yaml:"weight"`
+	Method  string            `yaml:"method,omitempty"`
+	Body    string            `yaml:"body,omitempty"`
+	Headers map[string]string `yaml:"headers,omitempty"`
+}
+
+type fileSLA struct {
+	Percentile  float64       `yaml:"percentile"`
+	MaxDuration time.Duration `yaml:"maxDuration"`
+}
+
+type fileHealthCheck struct {
+	Path           string `yaml:"path"`
+	ExpectedStatus int    `yaml:"expectedStatus"`
+}
+
+type fileSaturationThresholds struct {
+	MinSuccessRate float64       `yaml:"minSuccessRate"`
+	MaxP99         time.Duration `yaml:"maxP99,omitempty"`
+}
+
+type fileBreakpoint struct {
+	Step         int           `yaml:"step"`
+	StepDuration time.Duration `yaml:"stepDuration"`
+	Threshold    float64       `yaml:"threshold"`
+}
+
+type fileInfluxDBOutput struct {
+	Path        string `yaml:"path"`
+	Measurement string `yaml:"measurement,omitempty"`
+}
+
+type fileStatsD struct {
+	Addr   string `yaml:"addr"`
+	Prefix string `yaml:"prefix,omitempty"`
+}
+
+type fileSlack struct {
+	URL string `yaml:"url"`
+	// always, slaFailed, aborted
+	NotifyOn []string `yaml:"notifyOn"`
+}
+
+type fileWebhook struct {
+	URL          string `yaml:"url"`
+	BodyTemplate string `yaml:"bodyTemplate"`
+}
+
+// Имена NotifyTrigger в YAML
+var notifyTriggerNames = map[NotifyTrigger]string{
+	NotifyAlways:    "always",
+	NotifySLAFailed: "slaFailed",
+	NotifyAborted:   "aborted",
+}
+
+func (f *fileSlack) option() Option {
+	var notifyOn NotifyTrigger
+	for _, s := range f.NotifyOn {
+		var found bool
+		for t, name := range notifyTriggerNames {
+			if name == s {
+				notifyOn |= t
+				found = true
+			}
+		}
+		if !found {
+			return func(c *Config) { c.fail(fmt.Errorf("slack webhook: unknown notify trigger %q", s)) }
+		}
+	}
+	return WithSlackWebhook(f.URL, notifyOn)
+}
+
+func newFileSlack(url string, notifyOn NotifyTrigger) *fileSlack {
+	f := &fileSlack{URL: url}
+	for t := NotifyAlways; t <= NotifyAborted; t <<= 1 {
+		if notifyOn&t != 0 {
+			f.NotifyOn = append(f.NotifyOn, notifyTriggerNames[t])
+		}
+	}
+	return f
+}
+
+// Читает конфигурацию из YAML файла. Значения проверяются так же, как
+// в опциях With*, незнакомые ключи - ошибка. Результат передается в Test
+// через WithConfig
+func LoadConfig(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read config: %w", err)
+	}
+	return parseConfig(data, path)
+}
+
+// Разбирает YAML конфигурацию, name - имя источника для ошибок
+func parseConfig(data []byte, name string) (*Config, error) {
+	var f fileConfig
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&f); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("parse config %s: %w", name, err)
+	}
+
+	cfg := defaultConfig()
+	for _, opt := range f.options() {
+		opt(&cfg)
+	}
+	if cfg.err != nil {
+		return nil, fmt.Errorf("config %s: %w", name, cfg.err)
+	}
+	return &cfg, nil
+}
+
+// Опции, соответствующие заданным в файле значениям
+func (f *fileConfig) options() []Option {
+	var opts []Option
+	add := func(set bool, opt Option) {
+		if set {
+			opts = append(opts, opt)
+		}
+	}
+
+	add(f.Name != "", WithName(f.Name))
+	add(f.Method != "", WithMethod(f.Method))
+	add(f.Timeout != 0, WithTimeout(f.Timeout))
+	add(f.DialTimeout != 0, WithDialTimeout(f.DialTimeout))
+	add(f.ResponseTimeout != 0, WithResponseTimeout(f.ResponseTimeout))
+	add(f.Concurrency != 0, WithConcurrency(f.Concurrency))
+	add(f.RequestCount != 0, WithRequestCount(f.RequestCount))
+	add(f.Duration != 0, WithDuration(f.Duration))
+	add(f.RateLimit != 0, WithRateLimit(f.RateLimit))
+	add(f.Headers != nil, WithHeaders(f.Headers))
+	add(f.Verbose, WithVerbose(true))
+	add(f.Quiet, WithQuiet(true))
+
+	if f.Body != nil {
+		opts = append(opts, WithBody([]byte(f.Body.Content), f.Body.ContentType))
+	}
+	add(f.BodyFile != "", WithBodyFile(f.BodyFile))
+	add(f.BodyFileStreamed != "", WithBodyFileStreamed(f.BodyFileStreamed))
+	add(f.JSONSchemaBody != "", WithJSONSchemaBody(f.JSONSchemaBody))
+	add(f.CompressBody, WithCompressBody(true))
+	add(f.AcceptEncoding != "", WithAcceptEncoding(f.AcceptEncoding))
+
+	add(f.OutputFormat != "", WithOutputFormat(f.OutputFormat))
+	add(f.CSVLog != "", WithCSVLog(f.CSVLog))
+	add(f.GatlingLog != "", WithGatlingLog(f.GatlingLog))
+	if f.InfluxDBOutput != nil {
+		opts = append(opts, WithInfluxDBOutput(f.InfluxDBOutput.Path, f.InfluxDBOutput.Measurement))
+	}
+	if f.Progress != nil {
+		opts = append(opts, WithProgress(*f.Progress))
+	}
+	add(f.JUnitOutput != "", WithJUnitOutput(f.JUnitOutput))
+	add(f.Checkpoint != "", WithCheckpointFile(f.Checkpoint))
+	add(f.Histogram, WithHistogram(true))
+	add(f.Preview, WithPreview(true))
+	add(f.DryRun, WithDryRun(true))
+
+	add(f.CoordinatedOmissionCorrection, WithCoordinatedOmissionCorrection(true))
+	add(f.Warmup != 0, WithWarmup(f.Warmup))
+	add(f.WarmupRequests != 0, WithWarmupRequests(f.WarmupRequests))
+	add(f.RampUp != 0, WithRampUp(f.RampUp))
+	add(f.RampSteps != 0, WithRampSteps(f.RampSteps))
+	if f.ThinkTime != nil {
+		opts = append(opts, WithThinkTime(f.ThinkTime.Min, f.ThinkTime.Max))
+	}
+	add(f.PoissonThinkTime != 0, WithPoissonThinkTime(f.PoissonThinkTime))
+	add(f.Prewarm != 0, WithPrewarm(f.Prewarm))
+	if f.Retry != nil {
+		opts = append(opts, WithRetry(f.Retry.MaxRetries, f.Retry.Backoff))
+	}
+
+	add(f.TLSSkipVerify, WithTLSSkipVerify(true))
+	add(f.TLSCACert != "", WithTLSCACert(f.TLSCACert))
+	if f.ClientCert != nil {
+		opts = append(opts, WithClientCert(f.ClientCert.Cert, f.ClientCert.Key))
+	}
+	add(f.SNI != "", WithSNI(f.SNI))
+	if f.HTTP2 != nil {
+		opts = append(opts, WithHTTP2(*f.HTTP2))
+	}
+
+	add(f.Proxy != "", WithProxy(f.Proxy))
+	if f.SOCKS5Proxy != nil {
+		opts = append(opts, WithSOCKS5Proxy(f.SOCKS5Proxy.Addr, f.SOCKS5Proxy.Username, f.SOCKS5Proxy.Password))
+	}
+	add(f.IPv4Only, WithIPv4Only(true))
+	add(f.IPv6Only, WithIPv6Only(true))
+
+	add(f.DisableKeepAlive, WithDisableKeepAlive(true))
+	add(f.MaxIdleConns != 0, WithMaxIdleConns(f.MaxIdleConns))
+	add(f.MaxIdleConnsPerHost != 0, WithMaxIdleConnsPerHost(f.MaxIdleConnsPerHost))
+	add(f.MaxConnsPerHost != 0, WithMaxConnsPerHost(f.MaxConnsPerHost))
+	if f.MaxRedirects != nil {
+		opts = append(opts, WithMaxRedirects(*f.MaxRedirects))
+	}
+
+	if f.BasicAuth != nil {
+		opts = append(opts, WithBasicAuth(f.BasicAuth.Username, f.BasicAuth.Password))
+	}
+	add(f.BearerToken != "", WithBearerToken(f.BearerToken))
+	if f.APIKey != nil {
+		opts = append(opts, WithAPIKey(f.APIKey.Header, f.APIKey.Key))
+	}
+	add(f.CookieJar, WithCookieJar(true))
+	if len(f.Cookies) > 0 {
+		cookies := make([]*http.Cookie, 0, len(f.Cookies))
+		for _, name := range slices.Sorted(maps.Keys(f.Cookies)) {
+			cookies = append(cookies, &http.Cookie{Name: name, Value: f.Cookies[name]})
+		}
+		opts = append(opts, WithCookies(cookies))
+	}
+	add(f.UserAgent != "", WithUserAgent(f.UserAgent))
+	add(len(f.UserAgentRotation) > 0, WithUserAgentRotation(f.UserAgentRotation))
+	add(f.HostHeader != "", WithHostHeader(f.HostHeader))
+	add(f.RequestIDHeader != "", WithRequestIDHeader(f.RequestIDHeader))
+	add(f.CacheBust, WithCacheBust(true))
+	add(len(f.RandomQueryParams) > 0, WithRandomQueryParams(f.RandomQueryParams))
+
+	add(len(f.URLs) > 0, WithURLs(f.URLs))
+	add(f.URLFile != "", WithURLFile(f.URLFile))
+	add(f.HARFile != "", WithHARFile(f.HARFile))
+	add(f.HARThinkTime, WithHARThinkTime(true))
+	if t := f.URLTemplate; t != nil {
+		opts = append(opts, WithURLTemplate(t.Template, t.DataFile))
+	}
+	if s := f.OpenAPISpec; s != nil {
+		opts = append(opts, WithOpenAPISpec(s.Path, OpenAPITestOptions{
+			IncludeTags: s.IncludeTags,
+			ExcludeTags: s.ExcludeTags,
+			IncludePath: s.IncludePath,
+			ExcludePath: s.ExcludePath,
+		}))
+	}
+	if f.AccessLog != nil {
+		format, ok := accessLogFormatNames[f.AccessLog.Format]
+		if !ok {
+			name := f.AccessLog.Format
+			opts = append(opts, func(c *Config) { c.fail(fmt.Errorf("unknown access log format %q", name)) })
+		} else {
+			opts = append(opts, WithAccessLog(f.AccessLog.Path, format))
+		}
+	}
+	if len(f.WeightedURLs) > 0 {
+		entries := make([]WeightedURL, len(f.WeightedURLs))
+		for i, u := range f.WeightedURLs {
+			entries[i] = WeightedURL{URL: u.URL, Weight: u.Weight, Method: u.Method, Headers: u.Headers}
+			if u.Body != "" {
+				entries[i].Body = []byte(u.Body)
+			}
+		}
+		opts = append(opts, WithWeightedURLs(entries))
+	}
+
+	add(len(f.ExpectedStatus) > 0, WithExpectedStatus(f.ExpectedStatus...))
+	for _, name := range slices.Sorted(maps.Keys(f.RequiredHeaders)) {
+		opts = append(opts, WithRequiredHeader(name, f.RequiredHeaders[name]))
+	}
+	add(f.BodyRegex != "", WithBodyRegex(f.BodyRegex))
+	add(f.ValidateContentLength, WithValidateContentLength(true))
+	for _, s := range f.SLAAssertions {
+		opts = append(opts, WithSLAAssertion(s.Percentile, s.MaxDuration))
+	}
+	add(f.MaxFailureRate != 0, WithMaxFailureRate(f.MaxFailureRate))
+	add(f.ApdexThreshold != 0, WithApdexThreshold(f.ApdexThreshold))
+
+	if f.HealthCheck != nil {
+		opts = append(opts, WithHealthCheck(f.HealthCheck.Path, f.HealthCheck.ExpectedStatus))
+	}
+
+	if f.SaturationThresholds != nil {
+		opts = append(opts, WithSaturationThresholds(f.SaturationThresholds.MinSuccessRate, f.SaturationThresholds.MaxP99))
+	}
+
+	if f.Breakpoint != nil {
+		opts = append(opts, WithBreakpoint(f.Breakpoint.Step, f.Breakpoint.StepDuration, f.Breakpoint.Threshold))
+	}
+	for metric, pct := range f.RegressionThresholds {
+		opts = append(opts, WithRegressionThreshold(metric, pct))
+	}
+
+	add(f.PrometheusEndpoint != "", WithPrometheusEndpoint(f.PrometheusEndpoint))
+	add(f.PrometheusHistogramFile != "", WithPrometheusHistogramFile(f.PrometheusHistogramFile))
+	if f.StatsD != nil {
+		opts = append(opts, WithStatsD(f.StatsD.Addr, f.StatsD.Prefix))
+	}
+	add(f.ResourceTracking, WithResourceTracking(true))
+	add(f.CPUProfile != "", WithCPUProfile(f.CPUProfile))
+	add(f.HeapProfile != "", WithHeapProfile(f.HeapProfile))
+	if f.SlackWebhook != nil {
+		opts = append(opts, f.SlackWebhook.option())
+	}
+	if f.Webhook != nil {
+		opts = append(opts, WithWebhook(f.Webhook.URL, f.Webhook.BodyTemplate))
+	}
+	return opts
+}
+
+// Сохраняет конфигурацию в YAML в формате LoadConfig. Значения, которые
+// нельзя записать в файл (WithClient, WithContext, WithRequestHook,
+// WithWorkerInitHook, WithJWTRefresh, WithOutput, WithOTelTracerProvider,
+// WithLoadProfile), пропускаются
+func (c *Config) ToYAML() ([]byte, error) {
+	f := fileConfig{
+		Name:            c.Name,
+		Method:          c.Method,
+		Timeout:         c.Timeout,
+		DialTimeout:     c.DialTimeout,
+		ResponseTimeout: c.ResponseTimeout,
+		Concurrency:     c.Concurrency,
+		RequestCount:    c.Requests,
+		Duration:        c.Duration,
+		RateLimit:       c.RateLimit,
+		Headers:         c.Headers,
+		Verbose:         c.Verbose,
+		Quiet:           c.Quiet,
+
+		BodyFileStreamed: c.BodyFile,
+		JSONSchemaBody:   c.JSONSchemaBody,
+		CompressBody:     c.CompressBody,
+		AcceptEncoding:   c.AcceptEncoding,
+
+		OutputFormat: c.OutputFormat,
+		CSVLog:       c.CSVLog,
+		GatlingLog:   c.GatlingLog,
+		Progress:     &c.Progress,
+		JUnitOutput:  c.JUnitOutput,
+		Checkpoint:   c.CheckpointFile,
+		Histogram:    c.Histogram,
+		Preview:      c.Preview,
+		DryRun:       c.DryRun,
+
+		CoordinatedOmissionCorrection: c.CorrectCoordinatedOmission,
+		Warmup:                        c.Warmup,
+		WarmupRequests:                c.WarmupRequests,
+		RampUp:                        c.RampUp,
+		RampSteps:                     c.RampSteps,
+		PoissonThinkTime:              c.PoissonThinkTime,
+		Prewarm:                       c.Prewarm,
+
+		TLSSkipVerify: c.TLSSkipVerify,
+		TLSCACert:     c.TLSCACert,
+		SNI:           c.SNI,
+		HTTP2:         c.HTTP2,
+
+		Proxy:    c.Proxy,
+		IPv4Only: c.IPv4Only,
+		IPv6Only: c.IPv6Only,
+
+		DisableKeepAlive:    c.DisableKeepAlive,
+		MaxIdleConns:        c.MaxIdleConns,
+		MaxIdleConnsPerHost: c.MaxIdleConnsPerHost,
+		MaxConnsPerHost:     c.MaxConnsPerHost,
+		MaxRedirects:        &c.MaxRedirects,
+
+		BearerToken:       c.BearerToken,
+		CookieJar:         c.CookieJar,
+		UserAgent:         c.UserAgent,
+		UserAgentRotation: c.UserAgents,
+		HostHeader:        c.HostHeader,
+		RequestIDHeader:   c.RequestIDHeader,
+		CacheBust:         c.CacheBust,
+		RandomQueryParams: c.RandomQueryParams,
+
+		URLs:                  c.URLs,
+		HARThinkTime:          c.HARThinkTime,
+		ExpectedStatus:        c.ExpectedStatus,
+		RequiredHeaders:       c.RequiredHeaders,
+		ValidateContentLength: c.ValidateContentLength,
+		MaxFailureRate:        c.MaxFailureRate,
+		ApdexThreshold:        c.ApdexThreshold,
+
+		PrometheusEndpoint:      c.PrometheusAddr,
+		PrometheusHistogramFile: c.PrometheusHistogramFile,
+		ResourceTracking:        c.ResourceTracking,
+		CPUProfile:              c.CPUProfile,
+		HeapProfile:             c.HeapProfile,
+	}
+
+	if c.URLTemplate != "" {
+		f.URLTemplate = &fileURLTemplate{Template: c.URLTemplate, DataFile: c.URLTemplateData}
+	}
+	if c.Body != nil {
+		f.Body = &fileBody{Content: string(c.Body), ContentType: c.ContentType}
+	}
+	switch {
+	case c.ThinkTimeMax > 0:
+		f.ThinkTime = &fileThinkTime{Min: c.ThinkTimeMin, Max: c.ThinkTimeMax}
+	case c.HARThinkTime && c.harWaitMax > 0 && c.PoissonThinkTime == 0:
+		// Адреса HAR записываются как weightedURLs, диапазон пауз - явно
+		f.ThinkTime = &fileThinkTime{Min: c.harWaitMin, Max: c.harWaitMax}
+	}
+	if c.MaxRetries > 0 {
+		f.Retry = &fileRetry{MaxRetries: c.MaxRetries, Backoff: c.RetryBackoff}
+	}
+	if c.TLSClientCert != "" {
+		f.ClientCert = &fileClientCert{Cert: c.TLSClientCert, Key: c.TLSClientKey}
+	}
+	if c.SOCKS5Addr != "" {
+		f.SOCKS5Proxy = &fileSOCKS5Proxy{Addr: c.SOCKS5Addr, Username: c.SOCKS5User, Password: c.SOCKS5Password}
+	}
+	if c.BasicAuthUser != "" || c.BasicAuthPassword != "" {
+		f.BasicAuth = &fileBasicAuth{Username: c.BasicAuthUser, Password: c.BasicAuthPassword}
+	}
+	if c.APIKeyHeader != "" {
+		f.APIKey = &fileAPIKey{Header: c.APIKeyHeader, Key: c.APIKey}
+	}
+	if len(c.Cookies) > 0 {
+		f.Cookies = make(map[string]string, len(c.Cookies))
+		for _, ck := range c.Cookies {
+			f.Cookies[ck.Name] = ck.Value
+		}
+	}
+	for _, u := range c.WeightedURLs {
+		f.WeightedURLs = append(f.WeightedURLs, fileWeightedURL{
+			URL:     u.URL,
+			Weight:  u.Weight,
+			Method:  u.Method,
+			Body:    string(u.Body),
+			Headers: u.Headers,
+		})
+	}
+	if c.BodyRegex != nil {
+		f.BodyRegex = c.BodyRegex.String()
+	}
+	for _, s := range c.SLAs {
+		f.SLAAssertions = append(f.SLAAssertions, fileSLA(s))
+	}
+	if c.HealthCheckPath != "" {
+		f.HealthCheck = &fileHealthCheck{Path: c.HealthCheckPath, ExpectedStatus: c.HealthCheckStatus}
+	}
+	f.SaturationThresholds = &fileSaturationThresholds{
+		MinSuccessRate: c.SaturationMinSuccessRate,
+		MaxP99:         c.SaturationMaxP99,
+	}
+	f.Breakpoint = &fileBreakpoint{
+		Step:         c.BreakpointStep,
+		StepDuration: c.BreakpointStepDuration,
+		Threshold:    c.BreakpointThreshold,
+	}
+	f.RegressionThresholds = c.RegressionThresholds
+	if c.InfluxDBOutput != "" {
+		f.InfluxDBOutput = &fileInfluxDBOutput{Path: c.InfluxDBOutput, Measurement: c.InfluxDBMeasurement}
+	}
+	if c.StatsDAddr != "" {
+		f.StatsD = &fileStatsD{Addr: c.StatsDAddr, Prefix: c.StatsDPrefix}
+	}
+	if c.SlackWebhook != "" {
+		f.SlackWebhook = newFileSlack(c.SlackWebhook, c.SlackNotifyOn)
+	}
+	if c.WebhookURL != "" {
+		f.Webhook = &fileWebhook{URL: c.WebhookURL, BodyTemplate: c.WebhookTemplate}
+	}
+	return yaml.Marshal(&f)
+}
+
+// Копия настроек, которую следующие опции могут менять, не затрагивая
+// оригинал: опции дописывают в карты и срезы на месте
+func (c *Config) clone() Config {
+	d := *c
+	d.Body = slices.Clone(c.Body)
+	d.Headers = maps.Clone(c.Headers)
+	d.RandomQueryParams = maps.Clone(c.RandomQueryParams)
+	d.UserAgents = slices.Clone(c.UserAgents)
+	d.Cookies = slices.Clone(c.Cookies)
+	d.clientCerts = slices.Clone(c.clientCerts)
+	d.URLs = slices.Clone(c.URLs)
+	d.WeightedURLs = slices.Clone(c.WeightedURLs)
+	d.ExpectedStatus = slices.Clone(c.ExpectedStatus)
+	d.RequiredHeaders = maps.Clone(c.RequiredHeaders)
+	d.SLAs = slices.Clone(c.SLAs)
+	d.RegressionThresholds = maps.Clone(c.RegressionThresholds)
+	return d
+}
+
+// Настройки, загруженные LoadConfig. Заменяет все ранее заданные опции,
+// следующие опции применяются поверх. Concurrency и Requests из аргументов
+// Test сохраняются, если в файле они не заданы
+func WithConfig(src *Config) Option {
+	return func(c *Config) {
+		concurrency, requests := c.Concurrency, c.Requests
+		*c = src.clone()
+		if c.Concurrency == 0 {
+			c.Concurrency = concurrency
+		}
+		if c.Requests == 0 {
+			c.Requests = requests
+		}
+	}
+}
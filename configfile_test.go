@@ -0,0 +1,48 @@
+package gohttptest
+
+import (
+	"testing"
+	"time"
+)
+
+// Загруженный конфиг можно использовать повторно: опции поверх WithConfig
+// меняют копию, а не карты и срезы оригинала
+func TestWithConfigDoesNotModifySource(t *testing.T) {
+	loaded, err := parseConfig([]byte(`
+headers:
+  X-Base: "1"
+slaAssertions:
+  - percentile: 0.95
+    maxDuration: 1s
+`), "test.yaml")
+	if err != nil {
+		t.Fatalf("parseConfig: %v", err)
+	}
+	first, err := NewConfig(WithConfig(loaded),
+		WithHeaders(map[string]string{"X-Extra": "1"}),
+		WithSLAAssertion(0.99, 2*time.Second),
+		WithRequiredHeader("X-Id", ""),
+		WithRegressionThreshold("p99", 10))
+	if err != nil {
+		t.Fatalf("NewConfig: %v", err)
+	}
+	if _, ok := first.Headers["X-Extra"]; !ok {
+		t.Fatalf("first config has no X-Extra header: %v", first.Headers)
+	}
+	if len(first.SLAs) != 2 {
+		t.Fatalf("first config SLAs = %v, want 2 entries", first.SLAs)
+	}
+
+	if len(loaded.Headers) != 1 || len(loaded.SLAs) != 1 ||
+		loaded.RequiredHeaders != nil || loaded.RegressionThresholds != nil {
+		t.Errorf("source config modified: headers %v, SLAs %v, required %v, thresholds %v",
+			loaded.Headers, loaded.SLAs, loaded.RequiredHeaders, loaded.RegressionThresholds)
+	}
+	second, err := NewConfig(WithConfig(loaded))
+	if err != nil {
+		t.Fatalf("NewConfig: %v", err)
+	}
+	if _, ok := second.Headers["X-Extra"]; ok || len(second.SLAs) != 1 {
+		t.Errorf("second config sees options of the first: headers %v, SLAs %v", second.Headers, second.SLAs)
+	}
+}
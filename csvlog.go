@@ -0,0 +1,76 @@
+package gohttptest
+
+import (
+	"encoding/csv"
+	"os"
+	"strconv"
+)
+
+// Асинхронная запись результатов запросов в CSV файл
+type csvLog struct {
+	f    *os.File
+	w    *csv.Writer
+	rows chan result
+	done chan error
+}
+
+func newCSVLog(path string) (*csvLog, error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+
+	l := &csvLog{
+		f:    f,
+		w:    csv.NewWriter(f),
+		rows: make(chan result, 1024),
+		done: make(chan error, 1),
+	}
+	if err := l.w.Write([]string{"timestamp_unix_ns", "status_code", "duration_ns", "bytes", "error", "request_id"}); err != nil {
+		f.Close()
+		return nil, err
+	}
+
+	go l.run()
+	return l, nil
+}
+
+func (l *csvLog) run() {
+	var werr error
+	for res := range l.rows {
+		if werr != nil {
+			continue
+		}
+		errText := ""
+		if res.Error != nil {
+			errText = res.Error.Error()
+		}
+		werr = l.w.Write([]string{
+			strconv.FormatInt(res.Start.UnixNano(), 10),
+			strconv.Itoa(res.StatusCode),
+			strconv.FormatInt(int64(res.Duration), 10),
+			strconv.FormatInt(res.Bytes, 10),
+			errText,
+			res.RequestID,
+		})
+	}
+
+	l.w.Flush()
+	if werr == nil {
+		werr = l.w.Error()
+	}
+	if err := l.f.Close(); werr == nil {
+		werr = err
+	}
+	l.done <- werr
+}
+
+func (l *csvLog) write(res result) {
+	l.rows <- res
+}
+
+// Дописывает оставшиеся строки и закрывает файл
+func (l *csvLog) close() error {
+	close(l.rows)
+	return <-l.done
+}
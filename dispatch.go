@@ -0,0 +1,99 @@
+package gohttptest
+
+import (
+	"context"
+	"time"
+)
+
+// Задание для воркера
+type job struct {
+	// Запрос фазы прогрева, его результат не учитывается
+	warmup bool
+}
+
+// Раздает задания воркерам: сначала прогрев (если задан), затем либо ровно
+// cfg.Requests заданий, либо, если задан cfg.Duration, поток заданий до
+// истечения времени. В момент начала измерения вызывается onMeasure
+func dispatch(ctx context.Context, cfg *Config, onMeasure func()) <-chan job {
+	jobs := make(chan job, cfg.Concurrency)
+
+	go func() {
+		defer close(jobs)
+
+		send := func(j job) bool {
+			select {
+			case <-ctx.Done():
+				return false
+			case jobs <- j:
+				return true
+			}
+		}
+
+		warming := cfg.Warmup > 0 || cfg.WarmupRequests > 0
+		if cfg.Warmup > 0 {
+			if !sendFor(ctx, jobs, job{warmup: true}, cfg.Warmup) {
+				return
+			}
+		} else {
+			for range cfg.WarmupRequests {
+				if !send(job{warmup: true}) {
+					return
+				}
+			}
+		}
+		if warming {
+			cfg.infof("Starting measurement...\n")
+		}
+
+		onMeasure()
+
+		if cfg.Duration > 0 {
+			sendFor(ctx, jobs, job{}, cfg.Duration)
+			return
+		}
+		for range cfg.Requests {
+			if !send(job{}) {
+				return
+			}
+		}
+	}()
+
+	return jobs
+}
+
+// Отправляет задания j в течение d, возвращает false если контекст отменен
+func sendFor(ctx context.Context, jobs chan<- job, j job, d time.Duration) bool {
+	deadline := time.After(d)
+	for {
+		select {
+		case <-ctx.Done():
+			return false
+		case <-deadline:
+			return true
+		case jobs <- j:
+		}
+	}
+}
+
+// Постепенно увеличивает количество воркеров от одного до cfg.Concurrency
+// за cfg.RampUp, добавляя воркеры каждые cfg.RampUp/cfg.RampSteps.
+// Первый воркер должен быть запущен до вызова
+func rampUp(ctx context.Context, cfg *Config, startWorker func(workerID int)) {
+	steps := max(cfg.RampSteps, 1)
+	ticker := time.NewTicker(max(cfg.RampUp/time.Duration(steps), time.Millisecond))
+	defer ticker.Stop()
+
+	active := 1
+	for step := 1; step <= steps && active < cfg.Concurrency; step++ {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		target := max(cfg.Concurrency*step/steps, 1)
+		for ; active < target; active++ {
+			startWorker(active)
+		}
+	}
+}
@@ -0,0 +1,490 @@
+package gohttptest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"gopkg.in/yaml.v3"
+
+	"github.com/batman565/gohttptest/internal/distpb"
+)
+
+// Распределяет нагрузку config между узлами RunWorker и объединяет их
+// статистику, включая гистограммы и секунды, в один результат. Адреса теста
+// задаются в config через WithURLs или WithWeightedURLs полными адресами.
+// Конкурентность, количество запросов и WithRateLimit делятся между узлами
+// поровну. Настройки, которые читают или пишут локальные файлы (тела из
+// файлов, HAR, CSV, логи, профили), и метрики Prometheus и StatsD узлы
+// не принимают. SLA проверяются по объединенной статистике. opts задают
+// параметры соединения с узлами, без них соединение без TLS
+// (insecure.NewCredentials)
+func RunCoordinator(ctx context.Context, config Config, workerAddrs []string, opts ...grpc.DialOption) (BenchmarkResult, error) {
+	if config.err != nil {
+		return BenchmarkResult{}, config.err
+	}
+	n := len(workerAddrs)
+	if n == 0 {
+		return BenchmarkResult{}, errors.New("coordinator: no worker addresses")
+	}
+	if config.Concurrency < n {
+		return BenchmarkResult{}, fmt.Errorf("coordinator: concurrency %d is less than the number of workers %d", config.Concurrency, n)
+	}
+	if config.Requests <= 0 && config.Duration <= 0 {
+		return BenchmarkResult{}, errors.New("coordinator: request count or duration must be set")
+	}
+	data, err := config.ToYAML()
+	if err != nil {
+		return BenchmarkResult{}, fmt.Errorf("coordinator: %w", err)
+	}
+	if err := checkRemoteConfig(data); err != nil {
+		return BenchmarkResult{}, fmt.Errorf("coordinator: %w", err)
+	}
+	if len(opts) == 0 {
+		opts = []grpc.DialOption{grpc.WithTransportCredentials(insecure.NewCredentials())}
+	}
+	if config.Output == nil {
+		config.Output = os.Stdout
+	}
+	config.infof("Starting distributed benchmark on %d workers...\n", n)
+
+	responses := make([]*distpb.RunResponse, n)
+	errs := make([]error, n)
+	var wg sync.WaitGroup
+	for i, addr := range workerAddrs {
+		req := &distpb.RunRequest{
+			ConfigYaml:  data,
+			Concurrency: int32(share(config.Concurrency, n, i)),
+			Requests:    int64(share(config.Requests, n, i)),
+			RateLimit:   config.RateLimit / float64(n),
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			responses[i], errs[i] = runOnWorker(ctx, addr, req, opts)
+			if errs[i] != nil {
+				errs[i] = fmt.Errorf("worker %s: %w", addr, errs[i])
+			}
+		}()
+	}
+	wg.Wait()
+
+	st := newStats(&config)
+	var (
+		duration, warmup time.Duration
+		conns            int64
+		tracked          = true
+		reason           string
+	)
+	for i, resp := range responses {
+		if resp == nil {
+			continue
+		}
+		if resp.GetError() != "" {
+			errs[i] = fmt.Errorf("worker %s: %s", workerAddrs[i], resp.GetError())
+		}
+		if resp.GetStats() == nil {
+			continue
+		}
+		st.merge(statsFromProto(resp.GetStats()))
+		duration = max(duration, time.Duration(resp.GetTotalDurationNs()))
+		warmup = max(warmup, time.Duration(resp.GetWarmupDurationNs()))
+		conns += resp.GetNewConnections()
+		tracked = tracked && resp.GetConnTracked()
+		// Причина остановки любого узла важнее штатного завершения
+		if r := resp.GetAbortReason(); reason == "" || (r != StopRequestCount && r != StopDuration) {
+			reason = r
+		}
+	}
+	if err := errors.Join(errs...); err != nil && st.totalRequests == 0 {
+		return BenchmarkResult{}, err
+	}
+
+	bench := st.finish(duration)
+	bench.Name = config.Name
+	bench.WarmupDuration = warmup
+	bench.AbortReason = reason
+	if tracked {
+		bench.setConnections(conns)
+	}
+	slaErr := st.checkSLAs(config.SLAs)
+	if config.ErrorOutput == nil {
+		config.ErrorOutput = os.Stderr
+	}
+	name := config.Name
+	if name == "" {
+		name = "distributed"
+	}
+	notifyErr := notify(&config, name, bench, slaErr)
+	if err := writeReport(config.Output, config.OutputFormat, bench); err != nil {
+		return bench, err
+	}
+	return bench, errors.Join(append(errs, slaErr, notifyErr)...)
+}
+
+// Отклоняет конфигурацию узла с настройками, которые читают или пишут
+// файлы или открывают сокеты: иначе приславший ее мог бы перезаписать
+// файлы узла, отправить их содержимое на выбранный им адрес или занять
+// порт узла
+func checkRemoteConfig(data []byte) error {
+	var f fileConfig
+	if err := yaml.Unmarshal(data, &f); err != nil {
+		return fmt.Errorf("parse config: %w", err)
+	}
+	var names []string
+	add := func(set bool, name string) {
+		if set {
+			names = append(names, name)
+		}
+	}
+	add(f.BodyFile != "", "bodyFile")
+	add(f.BodyFileStreamed != "", "bodyFileStreamed")
+	add(f.JSONSchemaBody != "", "jsonSchemaBody")
+	add(f.CSVLog != "", "csvLog")
+	add(f.InfluxDBOutput != nil, "influxDBOutput")
+	add(f.GatlingLog != "", "gatlingLog")
+	add(f.JUnitOutput != "", "junitOutput")
+	add(f.Checkpoint != "", "checkpointFile")
+	add(f.TLSCACert != "", "tlsCACert")
+	add(f.ClientCert != nil, "clientCert")
+	add(f.URLFile != "", "urlFile")
+	add(f.HARFile != "", "harFile")
+	add(f.AccessLog != nil, "accessLog")
+	add(f.OpenAPISpec != nil, "openAPISpec")
+	add(f.URLTemplate != nil, "urlTemplate")
+	add(f.PrometheusHistogramFile != "", "prometheusHistogramFile")
+	add(f.CPUProfile != "", "cpuProfile")
+	add(f.HeapProfile != "", "heapProfile")
+	// Сокеты на узле: сервер метрик на выбранном порту и UDP на любой адрес
+	add(f.PrometheusEndpoint != "", "prometheusEndpoint")
+	add(f.StatsD != nil, "statsD")
+	if len(names) > 0 {
+		return fmt.Errorf("options with local files or sockets are not allowed in distributed mode: %s", strings.Join(names, ", "))
+	}
+	return nil
+}
+
+// Доля i-го из n узлов в total, остаток достается первым узлам
+func share(total, n, i int) int {
+	v := total / n
+	if i < total%n {
+		v++
+	}
+	return v
+}
+
+func runOnWorker(ctx context.Context, addr string, req *distpb.RunRequest, opts []grpc.DialOption) (*distpb.RunResponse, error) {
+	conn, err := grpc.NewClient(addr, opts...)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	return distpb.NewWorkerClient(conn).Run(ctx, req)
+}
+
+// Запускает gRPC сервер узла распределенного режима на listenAddr. Узел
+// выполняет тесты, присланные RunCoordinator, сам ничего не печатает, кроме
+// предупреждений. Настройки с локальными файлами и сокетами отклоняются.
+// opts передаются grpc.NewServer: без grpc.Creds узел принимает тесты от
+// любого, кто может к нему подключиться. Возвращается после отмены ctx
+func RunWorker(ctx context.Context, listenAddr string, opts ...grpc.ServerOption) error {
+	lis, err := net.Listen("tcp", listenAddr)
+	if err != nil {
+		return err
+	}
+	srv := grpc.NewServer(opts...)
+	distpb.RegisterWorkerServer(srv, &workerServer{})
+
+	done := make(chan error, 1)
+	go func() {
+		done <- srv.Serve(lis)
+	}()
+	select {
+	case <-ctx.Done():
+		srv.GracefulStop()
+		return nil
+	case err := <-done:
+		return err
+	}
+}
+
+type workerServer struct {
+	distpb.UnimplementedWorkerServer
+}
+
+func (s *workerServer) Run(ctx context.Context, req *distpb.RunRequest) (*distpb.RunResponse, error) {
+	// Проверка до parseConfig: опции читают файлы уже при разборе
+	if err := checkRemoteConfig(req.GetConfigYaml()); err != nil {
+		return nil, err
+	}
+	cfg, err := parseConfig(req.GetConfigYaml(), "from coordinator")
+	if err != nil {
+		return nil, err
+	}
+	cfg.Concurrency = int(req.GetConcurrency())
+	cfg.Requests = int(req.GetRequests())
+	cfg.RateLimit = req.GetRateLimit()
+	cfg.Context = ctx
+	cfg.Output = io.Discard
+	cfg.Progress = false
+	// SLA проверяет координатор по общей статистике
+	cfg.SLAs = nil
+	// Уведомление отправляет координатор
+	cfg.SlackWebhook = ""
+	cfg.webhookTemplate = nil
+	// Накопитель статистики берется из TestRun после завершения теста
+	cfg.testRun = &TestRun{}
+
+	bench, err := run(*cfg, "")
+	resp := &distpb.RunResponse{
+		TotalDurationNs:  int64(bench.TotalDuration),
+		WarmupDurationNs: int64(bench.WarmupDuration),
+		NewConnections:   bench.NewConnections,
+		ConnTracked:      bench.connTracked,
+		AbortReason:      bench.AbortReason,
+	}
+	if err != nil {
+		resp.Error = err.Error()
+	}
+	if st := cfg.testRun.st; st != nil {
+		resp.Stats = st.toProto()
+	}
+	return resp, nil
+}
+
+// Добавляет статистику другого узла
+func (s *stats) merge(o *stats) {
+	s.totalRequests += o.totalRequests
+	s.successCount += o.successCount
+	s.failedCount += o.failedCount
+	s.totalDuration += o.totalDuration
+	s.totalBytes += o.totalBytes
+	s.totalSent += o.totalSent
+	s.networkErrors += o.networkErrors
+	s.retries += o.retries
+	s.redirects += o.redirects
+	s.compressed += o.compressed
+	s.zipBytes += o.zipBytes
+	s.unzipBytes += o.unzipBytes
+	s.maxRedirects = max(s.maxRedirects, o.maxRedirects)
+	s.statusErrors += o.statusErrors
+	s.viaResponses += o.viaResponses
+	s.lengthErrors += o.lengthErrors
+	s.bodyErrors += o.bodyErrors
+	s.corrected += o.corrected
+	s.satisfied += o.satisfied
+	s.tolerating += o.tolerating
+	for k, v := range o.statusCodes {
+		s.statusCodes[k] += v
+	}
+	for k, v := range o.timeouts {
+		s.timeouts[k] += v
+	}
+	for k, v := range o.headerErrors {
+		s.headerErrors[k] += v
+	}
+	for k, v := range o.protocols {
+		s.protocols[k] += v
+	}
+	s.dns.merge(o.dns)
+	s.tls.merge(o.tls)
+	s.hist.merge(o.hist)
+	s.ttfb.merge(o.ttfb)
+	for len(s.series) < len(o.series) {
+		s.series = append(s.series, secondStats{})
+	}
+	for i, p := range o.series {
+		s.series[i].requests += p.requests
+		s.series[i].errors += p.errors
+		s.series[i].total += p.total
+	}
+	for i, u := range o.perURL {
+		if i >= len(s.perURL) {
+			s.perURL = append(s.perURL, URLStats{URL: u.URL})
+		}
+		s.perURL[i].TotalRequests += u.TotalRequests
+		s.perURL[i].SuccessCount += u.SuccessCount
+		s.perURL[i].FailedCount += u.FailedCount
+	}
+}
+
+func (p *phaseStats) merge(o phaseStats) {
+	if o.count == 0 {
+		return
+	}
+	if p.count == 0 || o.min < p.min {
+		p.min = o.min
+	}
+	p.max = max(p.max, o.max)
+	p.total += o.total
+	p.count += o.count
+}
+
+func (s *stats) toProto() *distpb.Stats {
+	pb := &distpb.Stats{
+		TotalRequests:   int64(s.totalRequests),
+		SuccessCount:    int64(s.successCount),
+		FailedCount:     int64(s.failedCount),
+		TotalDurationNs: int64(s.totalDuration),
+		TotalBytes:      s.totalBytes,
+		TotalSent:       s.totalSent,
+		NetworkErrors:   int64(s.networkErrors),
+		Retries:         int64(s.retries),
+		Redirects:       int64(s.redirects),
+		Compressed:      int64(s.compressed),
+		ZipBytes:        s.zipBytes,
+		UnzipBytes:      s.unzipBytes,
+		MaxRedirects:    int64(s.maxRedirects),
+		StatusErrors:    int64(s.statusErrors),
+		ViaResponses:    int64(s.viaResponses),
+		LengthErrors:    int64(s.lengthErrors),
+		BodyErrors:      int64(s.bodyErrors),
+		Corrected:       s.corrected,
+		Satisfied:       int64(s.satisfied),
+		Tolerating:      int64(s.tolerating),
+
+		StatusCodes:  make(map[int32]int64, len(s.statusCodes)),
+		Timeouts:     make(map[int32]int64, len(s.timeouts)),
+		HeaderErrors: make(map[string]int64, len(s.headerErrors)),
+		Protocols:    make(map[string]int64, len(s.protocols)),
+
+		Dns:       s.dns.toProto(),
+		Tls:       s.tls.toProto(),
+		Durations: s.hist.toProto(),
+		Ttfb:      s.ttfb.toProto(),
+	}
+	for k, v := range s.statusCodes {
+		pb.StatusCodes[int32(k)] = int64(v)
+	}
+	for k, v := range s.timeouts {
+		pb.Timeouts[int32(k)] = int64(v)
+	}
+	for k, v := range s.headerErrors {
+		pb.HeaderErrors[k] = int64(v)
+	}
+	for k, v := range s.protocols {
+		pb.Protocols[k] = int64(v)
+	}
+	for _, p := range s.series {
+		pb.Series = append(pb.Series, &distpb.Second{Requests: int64(p.requests), Errors: int64(p.errors), TotalNs: int64(p.total)})
+	}
+	for _, u := range s.perURL {
+		pb.PerUrl = append(pb.PerUrl, &distpb.URLCount{
+			Url:           u.URL,
+			TotalRequests: int64(u.TotalRequests),
+			SuccessCount:  int64(u.SuccessCount),
+			FailedCount:   int64(u.FailedCount),
+		})
+	}
+	return pb
+}
+
+// Статистика узла из сообщения. Пороги (Apdex, ожидаемые коды) не
+// передаются: они нужны только при добавлении запросов
+func statsFromProto(pb *distpb.Stats) *stats {
+	s := &stats{
+		totalRequests: int(pb.GetTotalRequests()),
+		successCount:  int(pb.GetSuccessCount()),
+		failedCount:   int(pb.GetFailedCount()),
+		totalDuration: time.Duration(pb.GetTotalDurationNs()),
+		totalBytes:    pb.GetTotalBytes(),
+		totalSent:     pb.GetTotalSent(),
+		networkErrors: int(pb.GetNetworkErrors()),
+		retries:       int(pb.GetRetries()),
+		redirects:     int(pb.GetRedirects()),
+		compressed:    int(pb.GetCompressed()),
+		zipBytes:      pb.GetZipBytes(),
+		unzipBytes:    pb.GetUnzipBytes(),
+		maxRedirects:  int(pb.GetMaxRedirects()),
+		statusErrors:  int(pb.GetStatusErrors()),
+		viaResponses:  int(pb.GetViaResponses()),
+		lengthErrors:  int(pb.GetLengthErrors()),
+		bodyErrors:    int(pb.GetBodyErrors()),
+		corrected:     pb.GetCorrected(),
+		satisfied:     int(pb.GetSatisfied()),
+		tolerating:    int(pb.GetTolerating()),
+
+		statusCodes:  make(map[int]int, len(pb.GetStatusCodes())),
+		timeouts:     make(map[timeoutKind]int, len(pb.GetTimeouts())),
+		headerErrors: make(map[string]int, len(pb.GetHeaderErrors())),
+		protocols:    make(map[string]int, len(pb.GetProtocols())),
+
+		dns:  phaseFromProto(pb.GetDns()),
+		tls:  phaseFromProto(pb.GetTls()),
+		hist: histogramFromProto(pb.GetDurations()),
+		ttfb: histogramFromProto(pb.GetTtfb()),
+	}
+	for k, v := range pb.GetStatusCodes() {
+		s.statusCodes[int(k)] = int(v)
+	}
+	for k, v := range pb.GetTimeouts() {
+		s.timeouts[timeoutKind(k)] = int(v)
+	}
+	for k, v := range pb.GetHeaderErrors() {
+		s.headerErrors[k] = int(v)
+	}
+	for k, v := range pb.GetProtocols() {
+		s.protocols[k] = int(v)
+	}
+	for _, p := range pb.GetSeries() {
+		s.series = append(s.series, secondStats{requests: int(p.GetRequests()), errors: int(p.GetErrors()), total: time.Duration(p.GetTotalNs())})
+	}
+	for _, u := range pb.GetPerUrl() {
+		s.perURL = append(s.perURL, URLStats{
+			URL:           u.GetUrl(),
+			TotalRequests: int(u.GetTotalRequests()),
+			SuccessCount:  int(u.GetSuccessCount()),
+			FailedCount:   int(u.GetFailedCount()),
+		})
+	}
+	return s
+}
+
+func (p phaseStats) toProto() *distpb.Phase {
+	return &distpb.Phase{Count: int64(p.count), TotalNs: int64(p.total), MinNs: int64(p.min), MaxNs: int64(p.max)}
+}
+
+func phaseFromProto(pb *distpb.Phase) phaseStats {
+	return phaseStats{
+		count: int(pb.GetCount()),
+		total: time.Duration(pb.GetTotalNs()),
+		min:   time.Duration(pb.GetMinNs()),
+		max:   time.Duration(pb.GetMaxNs()),
+	}
+}
+
+// Передаются только непустые подкорзины, параметры гистограммы у всех
+// узлов одинаковые
+func (h *histogram) toProto() *distpb.Histogram {
+	pb := &distpb.Histogram{Total: h.total, Min: h.min, Max: h.max, Mean: h.mean, M2: h.m2}
+	for i, c := range h.counts {
+		if c != 0 {
+			pb.Indexes = append(pb.Indexes, uint32(i))
+			pb.Counts = append(pb.Counts, c)
+		}
+	}
+	return pb
+}
+
+func histogramFromProto(pb *distpb.Histogram) *histogram {
+	h := newHistogram()
+	if pb.GetTotal() == 0 {
+		return h
+	}
+	for i, idx := range pb.GetIndexes() {
+		if int(idx) < len(h.counts) && i < len(pb.GetCounts()) {
+			h.counts[idx] = pb.GetCounts()[i]
+		}
+	}
+	h.total, h.min, h.max = pb.GetTotal(), pb.GetMin(), pb.GetMax()
+	h.mean, h.m2 = pb.GetMean(), pb.GetM2()
+	return h
+}
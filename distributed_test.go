@@ -0,0 +1,68 @@
+package gohttptest
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/batman565/gohttptest/internal/distpb"
+)
+
+// Узел отклоняет настройки с файлами и сокетами до разбора конфигурации
+func TestWorkerRejectsLocalOptions(t *testing.T) {
+	srv := NewMockServer(nil)
+	defer srv.Close()
+
+	config := "urls: [" + srv.URL() + "]\n" +
+		"csvLog: /tmp/worker.csv\n" +
+		"tlsCACert: /etc/passwd\n" +
+		"prometheusEndpoint: 127.0.0.1:0\n" +
+		"statsD: {addr: 127.0.0.1:8125}\n"
+	_, err := (&workerServer{}).Run(context.Background(), &distpb.RunRequest{
+		ConfigYaml:  []byte(config),
+		Concurrency: 1,
+		Requests:    1,
+	})
+	if err == nil {
+		t.Fatal("Run accepted config with local options")
+	}
+	for _, key := range []string{"csvLog", "tlsCACert", "prometheusEndpoint", "statsD"} {
+		if !strings.Contains(err.Error(), key) {
+			t.Errorf("error %q does not name %s", err, key)
+		}
+	}
+	if n := srv.RequestCount(); n != 0 {
+		t.Errorf("target got %d requests from rejected run", n)
+	}
+}
+
+// SLA и уведомления из конфигурации координатора узел не применяет
+func TestWorkerSanitizesCoordinatorOptions(t *testing.T) {
+	srv := NewMockServer(nil)
+	defer srv.Close()
+	hook := NewMockServer(nil)
+	defer hook.Close()
+
+	config := "urls: [" + srv.URL() + "]\n" +
+		"slaAssertions: [{percentile: 0.5, maxDuration: 1ns}]\n" +
+		"slackWebhook: {url: " + hook.URL() + ", notifyOn: [always]}\n" +
+		"webhook: {url: " + hook.URL() + ", bodyTemplate: '{}'}\n"
+	resp, err := (&workerServer{}).Run(context.Background(), &distpb.RunRequest{
+		ConfigYaml:  []byte(config),
+		Concurrency: 2,
+		Requests:    10,
+	})
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if resp.GetError() != "" {
+		t.Errorf("worker reported error %q, SLA must be checked by coordinator", resp.GetError())
+	}
+	if got := resp.GetStats().GetTotalRequests(); got != 10 {
+		t.Errorf("TotalRequests = %d, want 10", got)
+	}
+	WaitNotifications(context.Background())
+	if n := hook.RequestCount(); n != 0 {
+		t.Errorf("worker sent %d notifications", n)
+	}
+}
@@ -0,0 +1,51 @@
+package gohttptest
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"maps"
+	"net"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+)
+
+// Печатает заголовки, которые будут отправлены, и адреса целей без
+// отправки запросов. Ошибка, если имя цели не разрешается
+func dryRun(ctx context.Context, w io.Writer, cfg *Config, transport *http.Transport, targets []target) error {
+	req, _, err := newWorker(0, cfg, transport).newRequest(ctx, targets[0])
+	if err != nil {
+		return err
+	}
+	if req.Body != nil {
+		req.Body.Close()
+	}
+	headers := redactHeaders(req.Header, cfg)
+	if len(headers) > 0 {
+		fmt.Fprintln(w, "Headers:")
+		for _, name := range slices.Sorted(maps.Keys(headers)) {
+			fmt.Fprintf(w, "  %s: %s\n", name, strings.Join(headers[name], ", "))
+		}
+	}
+
+	resolved := make(map[string]bool)
+	for _, t := range targets {
+		u, err := url.Parse(t.url)
+		if err != nil {
+			return err
+		}
+		host := u.Hostname()
+		if resolved[host] {
+			continue
+		}
+		resolved[host] = true
+		addrs, err := net.DefaultResolver.LookupHost(ctx, host)
+		if err != nil {
+			return fmt.Errorf("resolve %s: %w", host, err)
+		}
+		fmt.Fprintf(w, "Resolved:    %s -> %s\n", host, strings.Join(addrs, ", "))
+	}
+	return nil
+}
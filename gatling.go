@@ -0,0 +1,116 @@
+package gohttptest
+
+import (
+	"bufio"
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Версия Gatling, формат simulation.log которой записывается
+const gatlingVersion = "3.9.5"
+
+// Замена табуляций и переводов строк в полях simulation.log
+var gatlingEscaper = strings.NewReplacer("\t", " ", "\n", " ", "\r", " ")
+
+// Строка лога: результат и признак успеха
+type gatlingRow struct {
+	res result
+	ok  bool
+}
+
+// Асинхронная запись результатов в текстовом формате simulation.log
+// Gatling 3.x (до перехода на бинарный формат): строка RUN, строки USER
+// START/END на каждого воркера и строка REQUEST на каждый запрос
+type gatlingLog struct {
+	f        *os.File
+	w        *bufio.Writer
+	names    []string
+	scenario string
+	rows     chan gatlingRow
+	done     chan error
+}
+
+// Имя запроса в отчете Gatling - метод и адрес цели
+func newGatlingLog(path, name string, targets []target, start time.Time) (*gatlingLog, error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, len(targets))
+	for i, t := range targets {
+		names[i] = gatlingEscaper.Replace(t.method + " " + displayURL(t.url))
+	}
+	scenario := "gohttptest"
+	if name != "" {
+		scenario = gatlingEscaper.Replace(name)
+	}
+	l := &gatlingLog{
+		f:        f,
+		w:        bufio.NewWriter(f),
+		names:    names,
+		scenario: scenario,
+		rows:     make(chan gatlingRow, 1024),
+		done:     make(chan error, 1),
+	}
+	fmt.Fprintf(l.w, "RUN\tgohttptest\t%s\t%d\t%s\t%s\n",
+		"gohttptest-"+strconv.FormatInt(start.UnixMilli(), 10), start.UnixMilli(), scenario, gatlingVersion)
+
+	go l.run()
+	return l, nil
+}
+
+func (l *gatlingLog) run() {
+	// Время первого и последнего запроса каждого воркера
+	type span struct{ start, end int64 }
+	users := make(map[int]*span)
+
+	for row := range l.rows {
+		res := row.res
+		start := res.Start.UnixMilli()
+		end := res.Start.Add(res.Duration).UnixMilli()
+
+		u := users[res.Worker]
+		if u == nil {
+			u = &span{start: start}
+			users[res.Worker] = u
+			fmt.Fprintf(l.w, "USER\t%s\tSTART\t%d\n", l.scenario, start)
+		}
+		u.end = max(u.end, end)
+
+		status, msg := "OK", " "
+		if !row.ok {
+			status = "KO"
+			msg = fmt.Sprintf("status %d", res.StatusCode)
+			if res.Error != nil {
+				msg = gatlingEscaper.Replace(res.Error.Error())
+			}
+		}
+		fmt.Fprintf(l.w, "REQUEST\t\t%s\t%d\t%d\t%s\t%s\n", l.names[res.URLIndex], start, end, status, msg)
+	}
+
+	for _, id := range slices.Sorted(maps.Keys(users)) {
+		fmt.Fprintf(l.w, "USER\t%s\tEND\t%d\n", l.scenario, users[id].end)
+	}
+
+	werr := l.w.Flush()
+	if err := l.f.Close(); werr == nil {
+		werr = err
+	}
+	l.done <- werr
+}
+
+func (l *gatlingLog) write(res result, ok bool) {
+	l.rows <- gatlingRow{res: res, ok: ok}
+}
+
+// Дописывает оставшиеся строки, строки END и закрывает файл
+func (l *gatlingLog) close() error {
+	close(l.rows)
+	return <-l.done
+}
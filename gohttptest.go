@@ -2,111 +2,415 @@ package gohttptest
 
 import (
 	"context"
+	"errors"
 	"flag"
 	"fmt"
 	"io"
+	"io/fs"
+	"maps"
+	"math/rand"
 	"net/http"
+	"net/url"
 	"os"
 	"os/signal"
 	"slices"
+	"strconv"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
+
+	"golang.org/x/time/rate"
 )
 
 /*
 	Метод тестирования API HTTP
 
-site-адрес переменной, count_p-количество параллельных запросов, count_r-количество запросов
+site-адрес переменной, count_p-количество параллельных запросов, count_r-количество запросов,
+opts-дополнительные настройки (WithMethod, WithTimeout...)
 */
-func Test(site string, count_p, count_r int) {
+func Test(site string, count_p, count_r int, opts ...Option) (BenchmarkResult, error) {
+
+	cfg := defaultConfig()
+	cfg.Concurrency = count_p
+	cfg.Requests = count_r
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	return run(cfg, site)
+}
+
+// Выполняет тест с готовой конфигурацией, site может быть пустым,
+// если заданы URLs или WeightedURLs
+func run(cfg Config, site string) (BenchmarkResult, error) {
+	if cfg.err != nil {
+		return BenchmarkResult{}, cfg.err
+	}
+	count_p, count_r := cfg.Concurrency, cfg.Requests
+	// Config, собранный вручную, может быть без writer
+	if cfg.Output == nil {
+		cfg.Output = os.Stdout
+	}
+	if cfg.ErrorOutput == nil {
+		cfg.ErrorOutput = os.Stderr
+	}
+	if cfg.VerboseOutput == nil {
+		cfg.VerboseOutput = os.Stderr
+	}
+	if cfg.Verbose {
+		cfg.Quiet = false
+	}
+	// Прогресс и служебные сообщения пишутся из разных горутин
+	cfg.Output = &syncWriter{w: cfg.Output}
+	cfg.ErrorOutput = &syncWriter{w: cfg.ErrorOutput}
 
-	if site == "" || count_p == 0 || count_r == 0 {
-		fmt.Println("Must be 3 values: -s, -c, -n. More --help")
+	if (site == "" && len(cfg.URLs) == 0 && len(cfg.WeightedURLs) == 0 && cfg.URLTemplate == "") || (count_p == 0 && cfg.LoadProfile == nil) || (count_r == 0 && cfg.Duration <= 0) {
+		fmt.Fprintln(cfg.ErrorOutput, "Must be 3 values: -s, -c, -n. More --help")
 		flag.PrintDefaults()
-		return
+		return BenchmarkResult{}, errors.New("site, concurrency and request count must be set")
 	}
 
-	if len(site) > 4 && site[:4] != "http" {
-		site = "http://" + site
+	if cfg.LoadProfile != nil && cfg.RampUp > 0 {
+		return BenchmarkResult{}, errors.New("WithLoadProfile and WithRampUp are mutually exclusive")
+	}
+
+	if cfg.IPv4Only && cfg.IPv6Only {
+		return BenchmarkResult{}, errors.New("WithIPv4Only and WithIPv6Only are mutually exclusive")
+	}
+
+	if cfg.CorrectCoordinatedOmission && cfg.RateLimit <= 0 {
+		return BenchmarkResult{}, errors.New("coordinated omission correction requires WithRateLimit")
+	}
+
+	method, err := normalizeMethod(cfg.Method)
+	if err != nil {
+		return BenchmarkResult{}, err
+	}
+
+	var ckpt *checkpoint
+	if cfg.CheckpointFile != "" {
+		done, err := readCheckpoint(cfg.CheckpointFile)
+		if err != nil {
+			return BenchmarkResult{}, fmt.Errorf("checkpoint: %w", err)
+		}
+		if done > 0 {
+			cfg.infof("Resuming from checkpoint: %d requests already recorded\n", done)
+			if cfg.Requests > 0 && done >= cfg.Requests {
+				cfg.infof("All %d requests are already recorded, nothing to send\n", cfg.Requests)
+				if err := os.Remove(cfg.CheckpointFile); err != nil && !errors.Is(err, fs.ErrNotExist) {
+					cfg.warnf("checkpoint: %v", err)
+				}
+				return BenchmarkResult{Name: cfg.Name, ResumedRequests: done, AbortReason: StopRequestCount}, nil
+			}
+			if cfg.Requests > 0 {
+				cfg.Requests -= done
+			}
+		}
+		ckpt = newCheckpoint(cfg.CheckpointFile, done)
+	}
+
+	if cfg.HARThinkTime && cfg.ThinkTimeMax == 0 && cfg.PoissonThinkTime == 0 {
+		cfg.ThinkTimeMin, cfg.ThinkTimeMax = cfg.harWaitMin, cfg.harWaitMax
+	}
+
+	if cfg.JWTRefresh != nil {
+		cfg.jwt = newJWTRefresher(cfg.BearerToken, cfg.JWTRefresh, cfg.JWTRefreshBefore)
+	}
+	if cfg.urlTemplate != nil {
+		cfg.templateRow = new(atomic.Uint64)
 	}
 
-	fmt.Printf("Starting benchmark...\n")
-	fmt.Printf("URL:         %s\n", site)
-	fmt.Printf("Concurrency: %d\n", count_p)
-	fmt.Printf("Requests:    %d\n\n", count_r)
+	if cfg.CacheBust {
+		cfg.cacheBust = new(atomic.Uint64)
+		// Случайное начало, чтобы значения не повторялись между запусками
+		cfg.cacheBust.Store(rand.Uint64())
+	}
+
+	// Размер тела до сжатия для заголовка отчета
+	bodySize := len(cfg.Body)
+	if cfg.CompressBody && cfg.Body != nil {
+		if cfg.Body, err = gzipBytes(cfg.Body); err != nil {
+			return BenchmarkResult{}, fmt.Errorf("compress body: %w", err)
+		}
+	}
+
+	if (cfg.Body != nil || cfg.BodyFile != "") && method == http.MethodGet {
+		cfg.warnf("request body is set for GET, not all servers accept it")
+	}
+
+	if cfg.TLSSkipVerify && cfg.Client == nil {
+		cfg.warnf("TLS certificate verification is disabled")
+		if cfg.SNI != "" {
+			cfg.warnf("SNI override with skip-verify disables certificate chain validation entirely")
+		}
+	}
+	if cfg.Client != nil {
+		for _, name := range cfg.transportOptions() {
+			cfg.warnf("%s is ignored because WithClient is set", name)
+		}
+		if cfg.Client.CheckRedirect != nil && cfg.MaxRedirects != defaultConfig().MaxRedirects {
+			cfg.warnf("WithMaxRedirects is ignored because the WithClient client has its own CheckRedirect")
+		}
+	}
+
+	site = normalizeSite(site)
+	targets, err := buildTargets(&cfg, site, method)
+	if err != nil {
+		return BenchmarkResult{}, err
+	}
+	parent := cfg.Context
+	if parent == nil {
+		parent = context.Background()
+	}
+	if cfg.Client == nil && cfg.proxyURL == nil && cfg.SOCKS5Addr == "" {
+		if err := checkIPFamily(parent, &cfg, targets); err != nil {
+			return BenchmarkResult{}, err
+		}
+	}
 
-	ctx, cancel := context.WithCancel(context.Background())
+	var (
+		conns     = new(atomic.Int64)
+		transport *http.Transport
+	)
+	switch {
+	case cfg.pipeline != nil && cfg.pipeline.transport != nil:
+		// Следующий этап RunPipeline на соединениях предыдущего
+		conns, transport = &cfg.pipeline.conns, cfg.pipeline.transport
+	case cfg.Client == nil:
+		if cfg.pipeline != nil {
+			conns = &cfg.pipeline.conns
+		}
+		transport, err = newTransport(&cfg, conns)
+		if err != nil {
+			return BenchmarkResult{}, err
+		}
+		if cfg.pipeline != nil {
+			// Соединения закрывает RunPipeline после последнего этапа
+			cfg.pipeline.transport = transport
+		} else {
+			defer transport.CloseIdleConnections()
+		}
+	}
+
+	if cfg.DryRun {
+		fmt.Fprintf(cfg.Output, "Dry run, no requests will be sent\n")
+		printHeader(cfg.Output, &cfg, targets, method, bodySize)
+		return BenchmarkResult{}, dryRun(parent, cfg.Output, &cfg, transport, targets)
+	}
+
+	if cfg.HealthCheckPath != "" {
+		if err := healthCheck(parent, &cfg, transport, targets[0].url); err != nil {
+			return BenchmarkResult{}, err
+		}
+	}
+
+	if cfg.Prewarm > 0 {
+		cfg.infof("Pre-warming %d connections...\n", cfg.Prewarm)
+		prewarm(parent, &cfg, transport, targets[0])
+	}
+	// Соединения прогрева не входят в статистику соединений теста
+	baseConns := conns.Load()
+
+	if !cfg.Quiet {
+		fmt.Fprintf(cfg.infoOutput(), "Starting benchmark...\n")
+		printHeader(cfg.infoOutput(), &cfg, targets, method, bodySize)
+		fmt.Fprintln(cfg.infoOutput())
+	}
+
+	if cfg.Preview {
+		if err := printPreview(parent, cfg.infoOutput(), &cfg, transport, targets[0]); err != nil {
+			return BenchmarkResult{}, fmt.Errorf("preview: %w", err)
+		}
+	}
+
+	ctx, cancel := context.WithCancel(parent)
 	defer cancel()
+	stopper := &stopper{cancel: cancel}
 
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, os.Interrupt)
+	defer signal.Stop(sigChan)
 	go func() {
-		<-sigChan
-		fmt.Println("\n\nInterrupt received, stopping...")
-		cancel()
+		select {
+		case <-sigChan:
+			cfg.infof("\n\nInterrupt received, stopping...\n")
+			stopper.stop(StopInterrupt)
+		case <-ctx.Done():
+		}
 	}()
 
-	results := make(chan result, count_r)
+	if cfg.jwt != nil {
+		go cfg.jwt.run(ctx, cfg.warnf)
+	}
+
+	results := make(chan result, max(count_r, count_p))
 	var wg sync.WaitGroup
 
 	startTime := time.Now()
 
-	jobs := make(chan struct{}, count_r)
-	for range count_r {
-		jobs <- struct{}{}
+	if cfg.Warmup > 0 || cfg.WarmupRequests > 0 {
+		cfg.infof("Warming up...\n")
+	}
+	st := newStats(&cfg)
+	if cfg.testRun != nil {
+		cfg.testRun.begin(startTime, st)
+	}
+	if cfg.PrometheusAddr != "" {
+		m, err := startMetrics(cfg.PrometheusAddr, st)
+		if err != nil {
+			return BenchmarkResult{}, fmt.Errorf("metrics endpoint: %w", err)
+		}
+		defer m.close()
+	}
+	var sd *statsdClient
+	if cfg.StatsDAddr != "" {
+		sd, err = newStatsD(cfg.StatsDAddr, cfg.StatsDPrefix, st)
+		if err != nil {
+			return BenchmarkResult{}, fmt.Errorf("statsd: %w", err)
+		}
+		defer sd.close()
+	}
+	// Логи создаются после шагов, которые могут завершиться ошибкой, и при
+	// ошибке создания закрываются уже открытые
+	var csvlog *csvLog
+	if cfg.CSVLog != "" {
+		csvlog, err = newCSVLog(cfg.CSVLog)
+		if err != nil {
+			return BenchmarkResult{}, fmt.Errorf("csv log: %w", err)
+		}
+	}
+	var influx *influxLog
+	if cfg.InfluxDBOutput != "" {
+		influx, err = newInfluxLog(cfg.InfluxDBOutput, cfg.InfluxDBMeasurement)
+		if err != nil {
+			if csvlog != nil {
+				csvlog.close()
+			}
+			return BenchmarkResult{}, fmt.Errorf("influxdb output: %w", err)
+		}
+	}
+	var gatling *gatlingLog
+	if cfg.GatlingLog != "" {
+		gatling, err = newGatlingLog(cfg.GatlingLog, cfg.Name, targets, time.Now())
+		if err != nil {
+			if csvlog != nil {
+				csvlog.close()
+			}
+			if influx != nil {
+				influx.abort()
+			}
+			return BenchmarkResult{}, fmt.Errorf("gatling log: %w", err)
+		}
+	}
+	var resources *resourceTracker
+	if cfg.ResourceTracking {
+		resources = newResourceTracker(startTime)
+	}
+	// Начало измерения: время и количество соединений, открытых при прогреве
+	type measureMark struct {
+		at    time.Time
+		conns int64
+	}
+	started := make(chan measureMark, 1)
+	prof := startProfiler(&cfg)
+	jobs := dispatch(ctx, &cfg, func() {
+		started <- measureMark{at: time.Now(), conns: conns.Load()}
+	})
+
+	var limiter *rate.Limiter
+	if cfg.RateLimit > 0 {
+		limiter = rate.NewLimiter(rate.Limit(cfg.RateLimit), 1)
 	}
-	close(jobs)
 
-	for i := range count_p {
+	pick := newPicker(targets)
+	// Закрывается, когда задания закончились
+	finished := make(chan struct{})
+	var finishOnce sync.Once
+	// quit закрывается, чтобы остановить воркер при снижении нагрузки профилем
+	startWorker := func(workerID int, quit <-chan struct{}) {
 		wg.Add(1)
-		go func(workerID int) {
+		st.liveWorkers.Add(1)
+		loop := func() {
 			defer wg.Done()
-
-			client := &http.Client{
-				Timeout: 10 * time.Second,
+			defer st.liveWorkers.Add(-1)
+
+			var (
+				w     *worker
+				fresh = true
+			)
+			if cfg.pipeline != nil {
+				w, fresh = cfg.pipeline.worker(workerID, &cfg, transport)
+			} else {
+				w = newWorker(workerID, &cfg, transport)
+			}
+			if fresh && cfg.WorkerInitHook != nil {
+				if wc := cfg.WorkerInitHook(workerID); wc != nil {
+					w.state = wc
+				}
 			}
 
-			for range jobs {
+			for {
 				select {
 				case <-ctx.Done():
 					return
-				default:
-					reqStart := time.Now()
-
-					req, err := http.NewRequestWithContext(ctx, "GET", site, nil)
-					if err != nil {
-						results <- result{
-							StatusCode: 0,
-							Duration:   time.Since(reqStart),
-							Error:      err,
-						}
-						continue
+				case <-quit:
+					return
+				case j, ok := <-jobs:
+					if !ok {
+						finishOnce.Do(func() { close(finished) })
+						return
 					}
-
-					resp, err := client.Do(req)
-					duration := time.Since(reqStart)
-
-					if err != nil {
-						results <- result{
-							StatusCode: 0,
-							Duration:   duration,
-							Error:      err,
+					if limiter != nil {
+						if err := limiter.Wait(ctx); err != nil {
+							return
 						}
-						continue
 					}
 
-					bodyBytes, _ := io.ReadAll(resp.Body)
-					resp.Body.Close()
-
-					results <- result{
-						StatusCode: resp.StatusCode,
-						Duration:   duration,
-						Bytes:      int64(len(bodyBytes)),
-						Error:      nil,
+					idx := pick()
+					res := w.doRetry(ctx, targets[idx])
+					// Запрос, прерванный остановкой теста, не ошибка сервера:
+					// в статистику попадают только запросы, завершенные полностью
+					if ctx.Err() != nil && errors.Is(res.Error, ctx.Err()) {
+						return
+					}
+					res.Worker = workerID
+					res.URLIndex = idx
+					res.Warmup = j.warmup
+					res.SecondBucket = time.Since(startTime).Truncate(time.Second)
+					results <- res
+
+					if !w.think(ctx) {
+						return
 					}
 				}
 			}
-		}(i)
+		}
+		// В RunPipeline горутина воркера продолжает работать между этапами
+		if cfg.pipeline != nil {
+			cfg.pipeline.exec(workerID, loop)
+		} else {
+			go loop()
+		}
+	}
+
+	switch {
+	case cfg.LoadProfile != nil:
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			runProfile(ctx, cfg.LoadProfile, finished, startWorker)
+		}()
+	case cfg.RampUp > 0:
+		startWorker(0, nil)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			rampUp(ctx, &cfg, func(workerID int) { startWorker(workerID, nil) })
+		}()
+	default:
+		for i := range count_p {
+			startWorker(i, nil)
+		}
 	}
 
 	go func() {
@@ -114,82 +418,283 @@ func Test(site string, count_p, count_r int) {
 		close(results)
 	}()
 
-	var (
-		totalRequests int
-		successCount  int
-		failedCount   int
-		totalDuration time.Duration
-		minDuration   = time.Hour
-		maxDuration   time.Duration
-		durations     []time.Duration
-		totalBytes    int64
-	)
-
+	var junit *junitLog
+	if cfg.JUnitOutput != "" {
+		junit = newJUnitLog(cfg.URLs, site, startTime)
+	}
+	var live *progress
+	if cfg.Progress && !cfg.Quiet {
+		live = startProgress(cfg.infoOutput(), st, startTime)
+	}
+	var guard *failureGuard
+	if cfg.MaxFailureRate > 0 {
+		guard = startFailureGuard(st, cfg.MaxFailureRate, func() {
+			cfg.infof("Failure rate threshold exceeded, aborting.\n")
+			stopper.stop(StopFailureRate)
+		})
+	}
 	for res := range results {
-		totalRequests++
-		totalDuration += res.Duration
-		totalBytes += res.Bytes
-
-		if res.Duration < minDuration {
-			minDuration = res.Duration
+		if cfg.Verbose {
+			logRequest(cfg.VerboseOutput, res, targets[res.URLIndex])
 		}
-		if res.Duration > maxDuration {
-			maxDuration = res.Duration
+		if res.Warmup {
+			continue
 		}
-
-		durations = append(durations, res.Duration)
-
-		if res.Error != nil || res.StatusCode >= 400 {
-			failedCount++
+		if cfg.testRun != nil {
+			cfg.testRun.add(res)
 		} else {
-			successCount++
+			st.add(res)
+		}
+		if ckpt != nil && ckpt.due(st.totalRequests) {
+			if err := ckpt.save(st.finish(time.Since(startTime))); err != nil {
+				cfg.warnf("checkpoint: %v", err)
+			}
+		}
+		if csvlog != nil {
+			csvlog.write(res)
+		}
+		if influx != nil {
+			influx.write(res)
+		}
+		if gatling != nil {
+			gatling.write(res, st.succeeded(res))
+		}
+		if sd != nil {
+			sd.record(res, st.succeeded(res))
+		}
+		if junit != nil {
+			junit.add(res, st.succeeded(res))
+		}
+	}
+	// Все воркеры завершились: results закрывается после wg.Wait
+	prof.stop()
+	if live != nil {
+		live.close()
+	}
+	if guard != nil {
+		guard.close()
+	}
+	if csvlog != nil {
+		if err := csvlog.close(); err != nil {
+			fmt.Fprintf(cfg.ErrorOutput, "Warning: csv log: %v\n", err)
+		}
+	}
+	if influx != nil {
+		if err := influx.close(); err != nil {
+			fmt.Fprintf(cfg.ErrorOutput, "Warning: influxdb output: %v\n", err)
+		}
+	}
+	if gatling != nil {
+		if err := gatling.close(); err != nil {
+			fmt.Fprintf(cfg.ErrorOutput, "Warning: gatling log: %v\n", err)
 		}
 	}
 
-	totalTestTime := time.Since(startTime)
-
-	var p50, p90, p95, p99 time.Duration
-	if len(durations) > 0 {
-		slices.Sort(durations)
+	mark := measureMark{at: startTime, conns: baseConns}
+	select {
+	case mark = <-started:
+	default:
+	}
 
-		p50 = durations[int(float64(len(durations))*0.50)]
-		p90 = durations[int(float64(len(durations))*0.90)]
-		p95 = durations[int(float64(len(durations))*0.95)]
-		p99 = durations[int(float64(len(durations))*0.99)]
+	bench := st.finish(time.Since(mark.at))
+	if cfg.Warmup > 0 || cfg.WarmupRequests > 0 {
+		bench.WarmupDuration = mark.at.Sub(startTime)
+	}
+	if cfg.Client == nil {
+		bench.setConnections(conns.Load() - mark.conns)
+	}
+	bench.Name = cfg.Name
+	if resources != nil {
+		resources.close(&bench)
+	}
+	if cfg.testRun != nil {
+		cfg.testRun.annotate(&bench)
+	}
+	bench.AbortReason = stopper.stopReason()
+	switch {
+	case bench.AbortReason != "":
+	case parent.Err() != nil:
+		bench.AbortReason = StopContext
+	case cfg.Duration > 0:
+		bench.AbortReason = StopDuration
+	default:
+		bench.AbortReason = StopRequestCount
+	}
+	if ckpt != nil {
+		bench.ResumedRequests = ckpt.resumed
+		if bench.AbortReason == StopInterrupt || bench.AbortReason == StopContext {
+			if err := ckpt.save(bench); err != nil {
+				cfg.warnf("checkpoint: %v", err)
+			}
+		} else if err := os.Remove(ckpt.path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			cfg.warnf("checkpoint: %v", err)
+		}
+	}
+	slaErr := st.checkSLAs(cfg.SLAs)
+	name := cfg.Name
+	if name == "" {
+		name = site
+	}
+	notifyErr := notify(&cfg, name, bench, slaErr)
+	if err := writeReport(cfg.Output, cfg.OutputFormat, bench); err != nil {
+		return bench, err
+	}
+	if junit != nil {
+		if err := junit.write(cfg.JUnitOutput, bench); err != nil {
+			return bench, fmt.Errorf("junit output: %w", err)
+		}
 	}
+	if cfg.PrometheusHistogramFile != "" {
+		if err := writeHistogramFile(cfg.PrometheusHistogramFile, st); err != nil {
+			return bench, fmt.Errorf("prometheus histogram file: %w", err)
+		}
+	}
+	return bench, errors.Join(slaErr, notifyErr)
+}
 
-	fmt.Println("BENCHMARK RESULTS")
+// Печатает настройки теста перед запуском
+func printHeader(w io.Writer, cfg *Config, targets []target, method string, bodySize int) {
+	switch {
+	case cfg.urlTemplate != nil:
+		// displayURL экранировал бы фигурные скобки подстановок
+		fmt.Fprintf(w, "URL:         %s (%d rows from %s)\n", targets[0].url, len(cfg.urlTemplate.rows), cfg.URLTemplateData)
+	case len(targets) == 1:
+		fmt.Fprintf(w, "URL:         %s\n", displayURL(targets[0].url))
+	default:
+		for i, t := range targets {
+			label := "URLs:"
+			if i > 0 {
+				label = ""
+			}
+			line := displayURL(t.url)
+			if t.weight > 0 {
+				line += fmt.Sprintf(" (weight %d, %s)", t.weight, t.method)
+			}
+			fmt.Fprintf(w, "%-13s%s\n", label, line)
+		}
+	}
+	fmt.Fprintf(w, "Method:      %s\n", method)
+	if cfg.HostHeader != "" {
+		fmt.Fprintf(w, "Host:        %s\n", cfg.HostHeader)
+	}
+	if cfg.SNI != "" {
+		fmt.Fprintf(w, "SNI:         %s\n", cfg.SNI)
+	}
+	if cfg.proxyURL != nil {
+		fmt.Fprintf(w, "Proxy:       %s\n", cfg.proxyURL.Redacted())
+	}
+	if cfg.SOCKS5Addr != "" {
+		fmt.Fprintf(w, "Proxy:       socks5://%s\n", cfg.SOCKS5Addr)
+	}
+	if cfg.JWTRefresh != nil {
+		fmt.Fprintf(w, "Auth:        JWT, refreshed %v before expiry\n", cfg.JWTRefreshBefore)
+	}
+	if cfg.Body != nil {
+		if cfg.CompressBody {
+			fmt.Fprintf(w, "Body:        %d bytes, %d bytes gzip\n", bodySize, len(cfg.Body))
+		} else {
+			fmt.Fprintf(w, "Body:        %d bytes\n", len(cfg.Body))
+		}
+	}
+	if cfg.BodyFile != "" {
+		fmt.Fprintf(w, "Body:        %s (streamed)\n", cfg.BodyFile)
+	}
+	if len(cfg.RandomQueryParams) > 0 {
+		fmt.Fprintf(w, "Query:       random %s\n", strings.Join(slices.Sorted(maps.Keys(cfg.RandomQueryParams)), ", "))
+	}
+	if cfg.bodySchema != nil && cfg.Body == nil && cfg.BodyFile == "" {
+		fmt.Fprintf(w, "Body:        generated from JSON schema %s\n", cfg.JSONSchemaBody)
+	}
+	if len(cfg.ExpectedStatus) > 0 {
+		codes := make([]string, len(cfg.ExpectedStatus))
+		for i, code := range cfg.ExpectedStatus {
+			codes[i] = strconv.Itoa(code)
+		}
+		fmt.Fprintf(w, "Expect:      status %s\n", strings.Join(codes, ", "))
+	}
+	if cfg.LoadProfile != nil {
+		fmt.Fprintf(w, "Profile:     %v\n", cfg.LoadProfile)
+	} else {
+		fmt.Fprintf(w, "Concurrency: %d\n", cfg.Concurrency)
+	}
+	if cfg.DisableKeepAlive {
+		fmt.Fprintf(w, "Keep-alive:  disabled\n")
+	}
+	if cfg.RampUp > 0 {
+		fmt.Fprintf(w, "Ramp-up:     %v in %d steps\n", cfg.RampUp, cfg.RampSteps)
+	}
+	if cfg.Duration > 0 {
+		fmt.Fprintf(w, "Duration:    %v\n", cfg.Duration)
+	} else {
+		fmt.Fprintf(w, "Requests:    %d\n", cfg.Requests)
+	}
+	if cfg.ThinkTimeMax > 0 {
+		fmt.Fprintf(w, "Think time:  %v - %v\n", cfg.ThinkTimeMin, cfg.ThinkTimeMax)
+	}
+	if cfg.PoissonThinkTime > 0 {
+		fmt.Fprintf(w, "Think time:  exponential, mean %v\n", cfg.PoissonThinkTime)
+	}
+	if cfg.RateLimit > 0 {
+		fmt.Fprintf(w, "Rate limit:  %.2f req/s\n", cfg.RateLimit)
+		if cfg.CorrectCoordinatedOmission {
+			fmt.Fprintf(w, "Coordinated omission correction enabled\n")
+		}
+	}
+	if cfg.PrometheusAddr != "" {
+		fmt.Fprintf(w, "Metrics:     http://%s/metrics\n", cfg.PrometheusAddr)
+	}
+}
 
-	fmt.Printf("Time taken:           %v\n", totalTestTime.Round(time.Millisecond))
-	fmt.Printf("Total requests:       %d\n", totalRequests)
-	fmt.Printf("Successful requests:  %d\n", successCount)
-	fmt.Printf("Failed requests:      %d\n", failedCount)
-	fmt.Printf("Requests per second:  %.2f\n", float64(totalRequests)/totalTestTime.Seconds())
+// Writer, безопасный для записи из нескольких горутин
+type syncWriter struct {
+	mu sync.Mutex
+	w  io.Writer
+}
 
-	if totalRequests > 0 {
-		avgDuration := totalDuration / time.Duration(totalRequests)
-		fmt.Printf("Average duration:     %v\n", avgDuration.Round(time.Microsecond))
-		fmt.Printf("Min duration:         %v\n", minDuration.Round(time.Microsecond))
-		fmt.Printf("Max duration:         %v\n", maxDuration.Round(time.Microsecond))
-		fmt.Printf("50th percentile:      %v\n", p50.Round(time.Microsecond))
-		fmt.Printf("90th percentile:      %v\n", p90.Round(time.Microsecond))
-		fmt.Printf("95th percentile:      %v\n", p95.Round(time.Microsecond))
-		fmt.Printf("99th percentile:      %v\n", p99.Round(time.Microsecond))
+func (s *syncWriter) Write(p []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.w.Write(p)
+}
 
-		if totalTestTime > 0 {
-			fmt.Printf("Throughput:           %.2f KB/s\n",
-				float64(totalBytes)/1024/totalTestTime.Seconds())
-		}
+// Добавляет схему http://, если она не указана
+func normalizeSite(site string) string {
+	if len(site) > 4 && site[:4] != "http" {
+		return "http://" + site
+	}
+	return site
+}
 
-		successRate := float64(successCount) / float64(totalRequests) * 100
-		fmt.Printf("Success rate:         %.1f%%\n", successRate)
+// Адрес для вывода в отчет, без логина и пароля
+func displayURL(site string) string {
+	u, err := url.Parse(site)
+	if err != nil {
+		return site
 	}
+	u.User = nil
+	return u.String()
+}
 
+// Допустимые HTTP методы
+var methods = []string{
+	http.MethodGet,
+	http.MethodHead,
+	http.MethodPost,
+	http.MethodPut,
+	http.MethodPatch,
+	http.MethodDelete,
+	http.MethodOptions,
+	http.MethodTrace,
 }
 
-type result struct {
-	StatusCode int
-	Duration   time.Duration
-	Bytes      int64
-	Error      error
+// Приводит метод к верхнему регистру и проверяет, что он известен
+func normalizeMethod(method string) (string, error) {
+	if method == "" {
+		return http.MethodGet, nil
+	}
+	m := strings.ToUpper(method)
+	if !slices.Contains(methods, m) {
+		return "", fmt.Errorf("unsupported HTTP method %q", method)
+	}
+	return m, nil
 }
@@ -0,0 +1,132 @@
+package gohttptest
+
+import (
+	"bytes"
+	"net/http"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// Отправляет себе os.Interrupt, когда на blocked придут n сигналов
+func interruptAfter(t *testing.T, blocked <-chan struct{}, n int) {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("os.Interrupt cannot be sent to the current process on windows")
+	}
+	go func() {
+		for range n {
+			<-blocked
+		}
+		p, err := os.FindProcess(os.Getpid())
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if err := p.Signal(os.Interrupt); err != nil {
+			t.Error(err)
+		}
+	}()
+}
+
+// Прерывание посреди теста: в результат попадают только завершенные
+// запросы, запросы, оборванные остановкой, не считаются ошибками
+func TestInterruptReportsCompletedRequests(t *testing.T) {
+	const (
+		completed   = 20
+		concurrency = 4
+	)
+	var served atomic.Int64
+	blocked := make(chan struct{}, concurrency)
+	srv := NewMockServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if served.Add(1) <= completed {
+			return
+		}
+		// Остальные запросы висят до остановки теста
+		blocked <- struct{}{}
+		<-r.Context().Done()
+	}))
+	defer srv.Close()
+	interruptAfter(t, blocked, concurrency)
+
+	var out bytes.Buffer
+	res, err := Test(srv.URL(), concurrency, 1000,
+		WithOutput(&out), WithErrorOutput(&out), WithProgress(false), WithTimeout(time.Minute))
+	if err != nil {
+		t.Fatalf("Test: %v", err)
+	}
+	if res.AbortReason != StopInterrupt {
+		t.Errorf("AbortReason = %q, want %q", res.AbortReason, StopInterrupt)
+	}
+	if res.TotalRequests != completed || res.SuccessCount != completed {
+		t.Errorf("TotalRequests = %d, SuccessCount = %d, want %d", res.TotalRequests, res.SuccessCount, completed)
+	}
+	if res.FailedCount != 0 || res.NetworkErrors != 0 {
+		t.Errorf("FailedCount = %d, NetworkErrors = %d, want in-flight requests dropped", res.FailedCount, res.NetworkErrors)
+	}
+	if got := srv.RequestCount(); got != completed+concurrency {
+		t.Errorf("server got %d requests, want %d", got, completed+concurrency)
+	}
+	if !strings.Contains(out.String(), "Test interrupted after") {
+		t.Errorf("report has no interruption note:\n%s", out.String())
+	}
+}
+
+// Ответ, тело которого клиент уже прочитал целиком, считается завершенным,
+// даже если обработчик на сервере еще не вернулся к моменту прерывания
+func TestInterruptCountsFullyReadResponses(t *testing.T) {
+	const (
+		completed   = 20
+		concurrency = 4
+		body        = "done"
+	)
+	var served atomic.Int64
+	blocked := make(chan struct{}, concurrency)
+	release := make(chan struct{})
+	srv := NewMockServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch n := served.Add(1); {
+		case n <= completed:
+			return
+		case n <= completed+concurrency:
+			// Тело отправлено полностью, обработчик еще не завершился.
+			// Connection: close, чтобы следующий запрос воркера не ждал
+			// освобождения этого соединения
+			w.Header().Set("Connection", "close")
+			w.Header().Set("Content-Length", strconv.Itoa(len(body)))
+			w.Write([]byte(body))
+			w.(http.Flusher).Flush()
+		default:
+			// Следующие запросы воркеры отправляют, только прочитав
+			// предыдущие ответы, и висят до остановки теста
+			blocked <- struct{}{}
+		}
+		select {
+		case <-r.Context().Done():
+		case <-release:
+		}
+	}))
+	defer srv.Close()
+	defer close(release)
+	interruptAfter(t, blocked, concurrency)
+
+	var out bytes.Buffer
+	res, err := Test(srv.URL(), concurrency, 1000,
+		WithOutput(&out), WithErrorOutput(&out), WithProgress(false), WithTimeout(time.Minute))
+	if err != nil {
+		t.Fatalf("Test: %v", err)
+	}
+	if res.AbortReason != StopInterrupt {
+		t.Errorf("AbortReason = %q, want %q", res.AbortReason, StopInterrupt)
+	}
+	const want = completed + concurrency
+	if res.TotalRequests != want || res.SuccessCount != want {
+		t.Errorf("TotalRequests = %d, SuccessCount = %d, want %d", res.TotalRequests, res.SuccessCount, want)
+	}
+	if res.FailedCount != 0 || res.NetworkErrors != 0 {
+		t.Errorf("FailedCount = %d, NetworkErrors = %d, want 0", res.FailedCount, res.NetworkErrors)
+	}
+}
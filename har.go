@@ -0,0 +1,111 @@
+package gohttptest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Заголовки HAR, которые выставляет транспорт или которые не имеют смысла
+// при повторе запроса
+var harSkipHeaders = []string{"Host", "Content-Length", "Connection", "Accept-Encoding"}
+
+// Часть формата HAR 1.2, нужная для повтора запросов
+type harFile struct {
+	Log struct {
+		Entries []struct {
+			Request struct {
+				Method  string `json:"method"`
+				URL     string `json:"url"`
+				Headers []struct {
+					Name  string `json:"name"`
+					Value string `json:"value"`
+				} `json:"headers"`
+				PostData *struct {
+					MimeType string `json:"mimeType"`
+					Text     string `json:"text"`
+				} `json:"postData"`
+			} `json:"request"`
+			Timings struct {
+				// Миллисекунды, -1 если неизвестно
+				Wait float64 `json:"wait"`
+			} `json:"timings"`
+		} `json:"entries"`
+	} `json:"log"`
+}
+
+// Читает запросы из HAR файла. Одинаковые запросы (метод, адрес, тело)
+// объединяются, вес - количество повторений в файле. Возвращает также
+// минимальное и максимальное timings.wait
+func readHAR(path string) ([]WeightedURL, time.Duration, time.Duration, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	var har harFile
+	if err := json.Unmarshal(data, &har); err != nil {
+		return nil, 0, 0, fmt.Errorf("parse %s: %w", path, err)
+	}
+
+	var (
+		entries          []WeightedURL
+		minWait, maxWait time.Duration
+		waits            int
+	)
+	index := make(map[string]int)
+	for _, e := range har.Log.Entries {
+		req := e.Request
+		if req.URL == "" {
+			continue
+		}
+		var body []byte
+		headers := make(map[string]string)
+		for _, h := range req.Headers {
+			// Псевдозаголовки HTTP/2 (:authority, :path...)
+			if strings.HasPrefix(h.Name, ":") {
+				continue
+			}
+			name := http.CanonicalHeaderKey(h.Name)
+			if !slices.Contains(harSkipHeaders, name) {
+				headers[name] = h.Value
+			}
+		}
+		if req.PostData != nil {
+			body = []byte(req.PostData.Text)
+			if _, ok := headers["Content-Type"]; !ok && req.PostData.MimeType != "" {
+				headers["Content-Type"] = req.PostData.MimeType
+			}
+		}
+
+		key := req.Method + " " + req.URL + "\x00" + string(body)
+		if i, ok := index[key]; ok {
+			entries[i].Weight++
+		} else {
+			index[key] = len(entries)
+			entries = append(entries, WeightedURL{
+				URL:     req.URL,
+				Weight:  1,
+				Method:  req.Method,
+				Body:    body,
+				Headers: headers,
+			})
+		}
+
+		if e.Timings.Wait >= 0 {
+			wait := time.Duration(e.Timings.Wait * float64(time.Millisecond))
+			if waits == 0 || wait < minWait {
+				minWait = wait
+			}
+			maxWait = max(maxWait, wait)
+			waits++
+		}
+	}
+	if len(entries) == 0 {
+		return nil, 0, 0, fmt.Errorf("%s contains no requests", path)
+	}
+	return entries, minWait, maxWait, nil
+}
@@ -0,0 +1,56 @@
+package gohttptest
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// Таймаут запроса проверки доступности
+const healthCheckTimeout = 5 * time.Second
+
+// Отправляет GET на cfg.HealthCheckPath хоста base и проверяет статус.
+// Запрос не входит в результаты теста
+func healthCheck(ctx context.Context, cfg *Config, transport *http.Transport, base string) error {
+	u, err := url.Parse(base)
+	if err != nil {
+		return err
+	}
+	ref, err := url.Parse(cfg.HealthCheckPath)
+	if err != nil {
+		return fmt.Errorf("health check path: %w", err)
+	}
+	target := u.ResolveReference(ref).String()
+
+	client := &http.Client{Transport: transport}
+	if cfg.Client != nil {
+		c := *cfg.Client
+		client = &c
+	}
+	client.Timeout = healthCheckTimeout
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		return err
+	}
+	if cfg.UserAgent != "" {
+		req.Header.Set("User-Agent", cfg.UserAgent)
+	}
+	if cfg.HostHeader != "" {
+		req.Host = cfg.HostHeader
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("health check failed: %w", err)
+	}
+	io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+
+	if resp.StatusCode != cfg.HealthCheckStatus {
+		return fmt.Errorf("health check failed: %d from %s", resp.StatusCode, cfg.HealthCheckPath)
+	}
+	return nil
+}
@@ -0,0 +1,219 @@
+package gohttptest
+
+import (
+	"math"
+	"math/bits"
+	"time"
+)
+
+// Параметры гистограммы: диапазон 1µs..30s, точность 3 значащих цифры
+const (
+	histLowest            = int64(time.Microsecond)
+	histHighest           = int64(30 * time.Second)
+	histSignificantDigits = 3
+)
+
+// Гистограмма задержек в духе HdrHistogram: логарифмические корзины,
+// внутри каждой - линейные подкорзины. Запись O(1), память фиксирована
+type histogram struct {
+	unitMagnitude               uint
+	subBucketHalfCountMagnitude uint
+	subBucketHalfCount          int
+	subBucketMask               int64
+	counts                      []int64
+
+	total int64
+	min   int64
+	max   int64
+
+	// Для среднего и дисперсии (алгоритм Уэлфорда)
+	mean float64
+	m2   float64
+}
+
+func newHistogram() *histogram {
+	largest := 2 * int64(math.Pow10(histSignificantDigits))
+	subBucketCountMagnitude := uint(math.Ceil(math.Log2(float64(largest))))
+	subBucketHalfCountMagnitude := subBucketCountMagnitude - 1
+	unitMagnitude := uint(math.Floor(math.Log2(float64(histLowest))))
+	subBucketCount := int64(1) << subBucketCountMagnitude
+
+	// Количество корзин, необходимое для покрытия histHighest
+	smallestUntrackable := subBucketCount << unitMagnitude
+	bucketCount := 1
+	for smallestUntrackable <= histHighest {
+		smallestUntrackable <<= 1
+		bucketCount++
+	}
+
+	return &histogram{
+		unitMagnitude:               unitMagnitude,
+		subBucketHalfCountMagnitude: subBucketHalfCountMagnitude,
+		subBucketHalfCount:          int(subBucketCount / 2),
+		subBucketMask:               (subBucketCount - 1) << unitMagnitude,
+		counts:                      make([]int64, (bucketCount+1)*int(subBucketCount/2)),
+		min:                         math.MaxInt64,
+	}
+}
+
+// Записывает одно значение
+func (h *histogram) record(d time.Duration) {
+	h.recordN(d, 1)
+}
+
+// Записывает значение n раз
+func (h *histogram) recordN(d time.Duration, n int64) {
+	v := min(max(int64(d), 0), histHighest)
+	h.counts[h.index(v)] += n
+
+	h.min = min(h.min, int64(d))
+	h.max = max(h.max, int64(d))
+
+	// n одинаковых значений - группа со средним d и нулевой дисперсией
+	total := h.total + n
+	delta := float64(d) - h.mean
+	h.m2 += delta * delta * float64(h.total) * float64(n) / float64(total)
+	h.mean += delta * float64(n) / float64(total)
+	h.total = total
+}
+
+// Записывает значение с поправкой на coordinated omission: если запрос
+// длился дольше ожидаемого интервала между запросами, добавляет значения,
+// которые получили бы запросы, не отправленные за это время
+// (как recordCorrectedValue в HdrHistogram). Возвращает число добавленных значений
+func (h *histogram) recordCorrected(d, interval time.Duration) int64 {
+	h.record(d)
+	if interval <= 0 {
+		return 0
+	}
+	var added int64
+	for missing := d - interval; missing >= interval; missing -= interval {
+		h.record(missing)
+		added++
+	}
+	return added
+}
+
+// Добавляет значения другой гистограммы
+func (h *histogram) merge(o *histogram) {
+	if o.total == 0 {
+		return
+	}
+	for i, c := range o.counts {
+		h.counts[i] += c
+	}
+
+	// Объединение среднего и дисперсии (Chan et al.)
+	n := float64(h.total + o.total)
+	delta := o.mean - h.mean
+	h.m2 += o.m2 + delta*delta*float64(h.total)*float64(o.total)/n
+	h.mean += delta * float64(o.total) / n
+
+	h.total += o.total
+	h.min = min(h.min, o.min)
+	h.max = max(h.max, o.max)
+}
+
+func (h *histogram) clone() *histogram {
+	c := *h
+	c.counts = append([]int64(nil), h.counts...)
+	return &c
+}
+
+func (h *histogram) count() int64 {
+	return h.total
+}
+
+func (h *histogram) minValue() time.Duration {
+	if h.total == 0 {
+		return 0
+	}
+	return time.Duration(h.min)
+}
+
+func (h *histogram) maxValue() time.Duration {
+	return time.Duration(h.max)
+}
+
+func (h *histogram) meanValue() float64 {
+	return h.mean
+}
+
+// Сумма всех значений, включая добавленные recordCorrected
+func (h *histogram) sum() time.Duration {
+	return time.Duration(h.mean * float64(h.total))
+}
+
+// Стандартное отклонение по генеральной совокупности
+func (h *histogram) stdDev() float64 {
+	if h.total == 0 {
+		return 0
+	}
+	return math.Sqrt(h.m2 / float64(h.total))
+}
+
+// Значение перцентиля p (0..1). Как и для отсортированного среза,
+// берется элемент с индексом int(n*p)
+func (h *histogram) percentile(p float64) time.Duration {
+	if h.total == 0 {
+		return 0
+	}
+	target := min(int64(float64(h.total)*p)+1, h.total)
+
+	var seen int64
+	for i, c := range h.counts {
+		seen += c
+		if seen >= target {
+			v := h.highestEquivalentValue(h.valueFromIndex(i))
+			return time.Duration(min(max(v, h.min), h.max))
+		}
+	}
+	return time.Duration(h.max)
+}
+
+// Количество значений не больше d
+func (h *histogram) countAtOrBelow(d time.Duration) int64 {
+	v := min(max(int64(d), 0), histHighest)
+	last := h.index(v)
+	var n int64
+	for i := 0; i <= last; i++ {
+		n += h.counts[i]
+	}
+	return n
+}
+
+// Обходит непустые подкорзины по возрастанию значения
+func (h *histogram) forEach(fn func(value time.Duration, count int64)) {
+	for i, c := range h.counts {
+		if c != 0 {
+			fn(time.Duration(h.valueFromIndex(i)), c)
+		}
+	}
+}
+
+func (h *histogram) index(v int64) int {
+	bucket := h.bucketIndex(v)
+	sub := int(v >> (uint(bucket) + h.unitMagnitude))
+	return ((bucket + 1) << h.subBucketHalfCountMagnitude) + (sub - h.subBucketHalfCount)
+}
+
+func (h *histogram) bucketIndex(v int64) int {
+	pow2Ceiling := 64 - bits.LeadingZeros64(uint64(v|h.subBucketMask))
+	return pow2Ceiling - int(h.unitMagnitude) - int(h.subBucketHalfCountMagnitude+1)
+}
+
+func (h *histogram) valueFromIndex(i int) int64 {
+	bucket := (i >> h.subBucketHalfCountMagnitude) - 1
+	sub := (i & (h.subBucketHalfCount - 1)) + h.subBucketHalfCount
+	if bucket < 0 {
+		sub -= h.subBucketHalfCount
+		bucket = 0
+	}
+	return int64(sub) << (uint(bucket) + h.unitMagnitude)
+}
+
+func (h *histogram) highestEquivalentValue(v int64) int64 {
+	bucket := h.bucketIndex(v)
+	size := int64(1) << (h.unitMagnitude + uint(bucket))
+	return v + size - 1
+}
@@ -0,0 +1,141 @@
+package gohttptest
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Экранирование имени measurement в line protocol
+var influxEscaper = strings.NewReplacer(",", `\,`, " ", `\ `)
+
+// Асинхронная запись результатов запросов в формате InfluxDB line protocol.
+// Строки пишутся во временный файл рядом с path после копии существующего
+// содержимого path, при закрытии файл переименовывается в path, чтобы
+// Telegraf не прочитал файл частично
+type influxLog struct {
+	path        string
+	measurement string
+	f           *os.File
+	w           *bufio.Writer
+	rows        chan result
+	done        chan error
+	// Тест не начался: временный файл удаляется без переименования
+	discard bool
+}
+
+func newInfluxLog(path, measurement string) (*influxLog, error) {
+	f, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*")
+	if err != nil {
+		return nil, err
+	}
+	// CreateTemp создает файл с правами 0600, Telegraf может работать
+	// от другого пользователя
+	if err := f.Chmod(0o644); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return nil, err
+	}
+	// Новые строки дописываются к уже существующему файлу
+	if err := copyExisting(f, path); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return nil, err
+	}
+
+	l := &influxLog{
+		path:        path,
+		measurement: influxEscaper.Replace(measurement),
+		f:           f,
+		w:           bufio.NewWriter(f),
+		rows:        make(chan result, 1024),
+		done:        make(chan error, 1),
+	}
+	go l.run()
+	return l, nil
+}
+
+// Копирует содержимое path в начало f, если файл есть
+func copyExisting(f *os.File, path string) error {
+	src, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	n, err := io.Copy(f, src)
+	if err != nil || n == 0 {
+		return err
+	}
+	// Последняя строка без перевода строки склеилась бы с первой новой
+	last := make([]byte, 1)
+	if _, err := f.ReadAt(last, n-1); err != nil {
+		return err
+	}
+	if last[0] != '\n' {
+		_, err = f.Write([]byte{'\n'})
+	}
+	return err
+}
+
+func (l *influxLog) run() {
+	var (
+		werr error
+		line []byte
+	)
+	for res := range l.rows {
+		if werr != nil {
+			continue
+		}
+		line = append(line[:0], l.measurement...)
+		line = append(line, ",status="...)
+		line = strconv.AppendInt(line, int64(res.StatusCode), 10)
+		line = append(line, ",worker="...)
+		line = strconv.AppendInt(line, int64(res.Worker), 10)
+		line = append(line, " duration="...)
+		line = strconv.AppendInt(line, int64(res.Duration), 10)
+		line = append(line, "i,bytes="...)
+		line = strconv.AppendInt(line, res.Bytes, 10)
+		line = append(line, "i "...)
+		line = strconv.AppendInt(line, res.Start.UnixNano(), 10)
+		line = append(line, '\n')
+		_, werr = l.w.Write(line)
+	}
+
+	if werr == nil {
+		werr = l.w.Flush()
+	}
+	if err := l.f.Close(); werr == nil {
+		werr = err
+	}
+	if werr == nil && !l.discard {
+		werr = os.Rename(l.f.Name(), l.path)
+	}
+	if werr != nil || l.discard {
+		os.Remove(l.f.Name())
+	}
+	l.done <- werr
+}
+
+func (l *influxLog) write(res result) {
+	l.rows <- res
+}
+
+// Дописывает оставшиеся строки и переименовывает файл в итоговый путь
+func (l *influxLog) close() error {
+	close(l.rows)
+	return <-l.done
+}
+
+// Закрывает лог без записи в итоговый путь
+func (l *influxLog) abort() {
+	l.discard = true
+	l.close()
+}
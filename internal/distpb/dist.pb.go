@@ -0,0 +1,924 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.6
+// 	protoc        (unknown)
+// source: dist.proto
+
+// Протокол распределенного режима: координатор отправляет узлам часть
+// нагрузки и собирает их накопленную статистику
+
+package distpb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type RunRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Настройки в формате LoadConfig
+	ConfigYaml []byte `protobuf:"bytes,1,opt,name=config_yaml,json=configYaml,proto3" json:"config_yaml,omitempty"`
+	// Доля узла в общей нагрузке
+	Concurrency   int32   `protobuf:"varint,2,opt,name=concurrency,proto3" json:"concurrency,omitempty"`
+	Requests      int64   `protobuf:"varint,3,opt,name=requests,proto3" json:"requests,omitempty"`
+	RateLimit     float64 `protobuf:"fixed64,4,opt,name=rate_limit,json=rateLimit,proto3" json:"rate_limit,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RunRequest) Reset() {
+	*x = RunRequest{}
+	mi := &file_dist_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RunRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RunRequest) ProtoMessage() {}
+
+func (x *RunRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_dist_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RunRequest.ProtoReflect.Descriptor instead.
+func (*RunRequest) Descriptor() ([]byte, []int) {
+	return file_dist_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *RunRequest) GetConfigYaml() []byte {
+	if x != nil {
+		return x.ConfigYaml
+	}
+	return nil
+}
+
+func (x *RunRequest) GetConcurrency() int32 {
+	if x != nil {
+		return x.Concurrency
+	}
+	return 0
+}
+
+func (x *RunRequest) GetRequests() int64 {
+	if x != nil {
+		return x.Requests
+	}
+	return 0
+}
+
+func (x *RunRequest) GetRateLimit() float64 {
+	if x != nil {
+		return x.RateLimit
+	}
+	return 0
+}
+
+type RunResponse struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	Stats            *Stats                 `protobuf:"bytes,1,opt,name=stats,proto3" json:"stats,omitempty"`
+	TotalDurationNs  int64                  `protobuf:"varint,2,opt,name=total_duration_ns,json=totalDurationNs,proto3" json:"total_duration_ns,omitempty"`
+	WarmupDurationNs int64                  `protobuf:"varint,3,opt,name=warmup_duration_ns,json=warmupDurationNs,proto3" json:"warmup_duration_ns,omitempty"`
+	NewConnections   int64                  `protobuf:"varint,4,opt,name=new_connections,json=newConnections,proto3" json:"new_connections,omitempty"`
+	ConnTracked      bool                   `protobuf:"varint,5,opt,name=conn_tracked,json=connTracked,proto3" json:"conn_tracked,omitempty"`
+	AbortReason      string                 `protobuf:"bytes,6,opt,name=abort_reason,json=abortReason,proto3" json:"abort_reason,omitempty"`
+	// Ошибка теста на узле, статистика при этом может быть заполнена
+	Error         string `protobuf:"bytes,7,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RunResponse) Reset() {
+	*x = RunResponse{}
+	mi := &file_dist_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RunResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RunResponse) ProtoMessage() {}
+
+func (x *RunResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_dist_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RunResponse.ProtoReflect.Descriptor instead.
+func (*RunResponse) Descriptor() ([]byte, []int) {
+	return file_dist_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *RunResponse) GetStats() *Stats {
+	if x != nil {
+		return x.Stats
+	}
+	return nil
+}
+
+func (x *RunResponse) GetTotalDurationNs() int64 {
+	if x != nil {
+		return x.TotalDurationNs
+	}
+	return 0
+}
+
+func (x *RunResponse) GetWarmupDurationNs() int64 {
+	if x != nil {
+		return x.WarmupDurationNs
+	}
+	return 0
+}
+
+func (x *RunResponse) GetNewConnections() int64 {
+	if x != nil {
+		return x.NewConnections
+	}
+	return 0
+}
+
+func (x *RunResponse) GetConnTracked() bool {
+	if x != nil {
+		return x.ConnTracked
+	}
+	return false
+}
+
+func (x *RunResponse) GetAbortReason() string {
+	if x != nil {
+		return x.AbortReason
+	}
+	return ""
+}
+
+func (x *RunResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+// Накопленная статистика узла, объединяется координатором
+type Stats struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	TotalRequests   int64                  `protobuf:"varint,1,opt,name=total_requests,json=totalRequests,proto3" json:"total_requests,omitempty"`
+	SuccessCount    int64                  `protobuf:"varint,2,opt,name=success_count,json=successCount,proto3" json:"success_count,omitempty"`
+	FailedCount     int64                  `protobuf:"varint,3,opt,name=failed_count,json=failedCount,proto3" json:"failed_count,omitempty"`
+	TotalDurationNs int64                  `protobuf:"varint,4,opt,name=total_duration_ns,json=totalDurationNs,proto3" json:"total_duration_ns,omitempty"`
+	TotalBytes      int64                  `protobuf:"varint,5,opt,name=total_bytes,json=totalBytes,proto3" json:"total_bytes,omitempty"`
+	TotalSent       int64                  `protobuf:"varint,6,opt,name=total_sent,json=totalSent,proto3" json:"total_sent,omitempty"`
+	NetworkErrors   int64                  `protobuf:"varint,7,opt,name=network_errors,json=networkErrors,proto3" json:"network_errors,omitempty"`
+	Retries         int64                  `protobuf:"varint,8,opt,name=retries,proto3" json:"retries,omitempty"`
+	Redirects       int64                  `protobuf:"varint,9,opt,name=redirects,proto3" json:"redirects,omitempty"`
+	Compressed      int64                  `protobuf:"varint,10,opt,name=compressed,proto3" json:"compressed,omitempty"`
+	ZipBytes        int64                  `protobuf:"varint,11,opt,name=zip_bytes,json=zipBytes,proto3" json:"zip_bytes,omitempty"`
+	UnzipBytes      int64                  `protobuf:"varint,12,opt,name=unzip_bytes,json=unzipBytes,proto3" json:"unzip_bytes,omitempty"`
+	MaxRedirects    int64                  `protobuf:"varint,13,opt,name=max_redirects,json=maxRedirects,proto3" json:"max_redirects,omitempty"`
+	StatusErrors    int64                  `protobuf:"varint,14,opt,name=status_errors,json=statusErrors,proto3" json:"status_errors,omitempty"`
+	ViaResponses    int64                  `protobuf:"varint,15,opt,name=via_responses,json=viaResponses,proto3" json:"via_responses,omitempty"`
+	LengthErrors    int64                  `protobuf:"varint,16,opt,name=length_errors,json=lengthErrors,proto3" json:"length_errors,omitempty"`
+	BodyErrors      int64                  `protobuf:"varint,17,opt,name=body_errors,json=bodyErrors,proto3" json:"body_errors,omitempty"`
+	Corrected       int64                  `protobuf:"varint,18,opt,name=corrected,proto3" json:"corrected,omitempty"`
+	Satisfied       int64                  `protobuf:"varint,19,opt,name=satisfied,proto3" json:"satisfied,omitempty"`
+	Tolerating      int64                  `protobuf:"varint,20,opt,name=tolerating,proto3" json:"tolerating,omitempty"`
+	StatusCodes     map[int32]int64        `protobuf:"bytes,21,rep,name=status_codes,json=statusCodes,proto3" json:"status_codes,omitempty" protobuf_key:"varint,1,opt,name=key" protobuf_val:"varint,2,opt,name=value"`
+	Timeouts        map[int32]int64        `protobuf:"bytes,22,rep,name=timeouts,proto3" json:"timeouts,omitempty" protobuf_key:"varint,1,opt,name=key" protobuf_val:"varint,2,opt,name=value"`
+	HeaderErrors    map[string]int64       `protobuf:"bytes,23,rep,name=header_errors,json=headerErrors,proto3" json:"header_errors,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"varint,2,opt,name=value"`
+	Protocols       map[string]int64       `protobuf:"bytes,24,rep,name=protocols,proto3" json:"protocols,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"varint,2,opt,name=value"`
+	Dns             *Phase                 `protobuf:"bytes,25,opt,name=dns,proto3" json:"dns,omitempty"`
+	Tls             *Phase                 `protobuf:"bytes,26,opt,name=tls,proto3" json:"tls,omitempty"`
+	Durations       *Histogram             `protobuf:"bytes,27,opt,name=durations,proto3" json:"durations,omitempty"`
+	Ttfb            *Histogram             `protobuf:"bytes,28,opt,name=ttfb,proto3" json:"ttfb,omitempty"`
+	Series          []*Second              `protobuf:"bytes,29,rep,name=series,proto3" json:"series,omitempty"`
+	PerUrl          []*URLCount            `protobuf:"bytes,30,rep,name=per_url,json=perUrl,proto3" json:"per_url,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *Stats) Reset() {
+	*x = Stats{}
+	mi := &file_dist_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Stats) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Stats) ProtoMessage() {}
+
+func (x *Stats) ProtoReflect() protoreflect.Message {
+	mi := &file_dist_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Stats.ProtoReflect.Descriptor instead.
+func (*Stats) Descriptor() ([]byte, []int) {
+	return file_dist_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *Stats) GetTotalRequests() int64 {
+	if x != nil {
+		return x.TotalRequests
+	}
+	return 0
+}
+
+func (x *Stats) GetSuccessCount() int64 {
+	if x != nil {
+		return x.SuccessCount
+	}
+	return 0
+}
+
+func (x *Stats) GetFailedCount() int64 {
+	if x != nil {
+		return x.FailedCount
+	}
+	return 0
+}
+
+func (x *Stats) GetTotalDurationNs() int64 {
+	if x != nil {
+		return x.TotalDurationNs
+	}
+	return 0
+}
+
+func (x *Stats) GetTotalBytes() int64 {
+	if x != nil {
+		return x.TotalBytes
+	}
+	return 0
+}
+
+func (x *Stats) GetTotalSent() int64 {
+	if x != nil {
+		return x.TotalSent
+	}
+	return 0
+}
+
+func (x *Stats) GetNetworkErrors() int64 {
+	if x != nil {
+		return x.NetworkErrors
+	}
+	return 0
+}
+
+func (x *Stats) GetRetries() int64 {
+	if x != nil {
+		return x.Retries
+	}
+	return 0
+}
+
+func (x *Stats) GetRedirects() int64 {
+	if x != nil {
+		return x.Redirects
+	}
+	return 0
+}
+
+func (x *Stats) GetCompressed() int64 {
+	if x != nil {
+		return x.Compressed
+	}
+	return 0
+}
+
+func (x *Stats) GetZipBytes() int64 {
+	if x != nil {
+		return x.ZipBytes
+	}
+	return 0
+}
+
+func (x *Stats) GetUnzipBytes() int64 {
+	if x != nil {
+		return x.UnzipBytes
+	}
+	return 0
+}
+
+func (x *Stats) GetMaxRedirects() int64 {
+	if x != nil {
+		return x.MaxRedirects
+	}
+	return 0
+}
+
+func (x *Stats) GetStatusErrors() int64 {
+	if x != nil {
+		return x.StatusErrors
+	}
+	return 0
+}
+
+func (x *Stats) GetViaResponses() int64 {
+	if x != nil {
+		return x.ViaResponses
+	}
+	return 0
+}
+
+func (x *Stats) GetLengthErrors() int64 {
+	if x != nil {
+		return x.LengthErrors
+	}
+	return 0
+}
+
+func (x *Stats) GetBodyErrors() int64 {
+	if x != nil {
+		return x.BodyErrors
+	}
+	return 0
+}
+
+func (x *Stats) GetCorrected() int64 {
+	if x != nil {
+		return x.Corrected
+	}
+	return 0
+}
+
+func (x *Stats) GetSatisfied() int64 {
+	if x != nil {
+		return x.Satisfied
+	}
+	return 0
+}
+
+func (x *Stats) GetTolerating() int64 {
+	if x != nil {
+		return x.Tolerating
+	}
+	return 0
+}
+
+func (x *Stats) GetStatusCodes() map[int32]int64 {
+	if x != nil {
+		return x.StatusCodes
+	}
+	return nil
+}
+
+func (x *Stats) GetTimeouts() map[int32]int64 {
+	if x != nil {
+		return x.Timeouts
+	}
+	return nil
+}
+
+func (x *Stats) GetHeaderErrors() map[string]int64 {
+	if x != nil {
+		return x.HeaderErrors
+	}
+	return nil
+}
+
+func (x *Stats) GetProtocols() map[string]int64 {
+	if x != nil {
+		return x.Protocols
+	}
+	return nil
+}
+
+func (x *Stats) GetDns() *Phase {
+	if x != nil {
+		return x.Dns
+	}
+	return nil
+}
+
+func (x *Stats) GetTls() *Phase {
+	if x != nil {
+		return x.Tls
+	}
+	return nil
+}
+
+func (x *Stats) GetDurations() *Histogram {
+	if x != nil {
+		return x.Durations
+	}
+	return nil
+}
+
+func (x *Stats) GetTtfb() *Histogram {
+	if x != nil {
+		return x.Ttfb
+	}
+	return nil
+}
+
+func (x *Stats) GetSeries() []*Second {
+	if x != nil {
+		return x.Series
+	}
+	return nil
+}
+
+func (x *Stats) GetPerUrl() []*URLCount {
+	if x != nil {
+		return x.PerUrl
+	}
+	return nil
+}
+
+type Phase struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Count         int64                  `protobuf:"varint,1,opt,name=count,proto3" json:"count,omitempty"`
+	TotalNs       int64                  `protobuf:"varint,2,opt,name=total_ns,json=totalNs,proto3" json:"total_ns,omitempty"`
+	MinNs         int64                  `protobuf:"varint,3,opt,name=min_ns,json=minNs,proto3" json:"min_ns,omitempty"`
+	MaxNs         int64                  `protobuf:"varint,4,opt,name=max_ns,json=maxNs,proto3" json:"max_ns,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Phase) Reset() {
+	*x = Phase{}
+	mi := &file_dist_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Phase) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Phase) ProtoMessage() {}
+
+func (x *Phase) ProtoReflect() protoreflect.Message {
+	mi := &file_dist_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Phase.ProtoReflect.Descriptor instead.
+func (*Phase) Descriptor() ([]byte, []int) {
+	return file_dist_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *Phase) GetCount() int64 {
+	if x != nil {
+		return x.Count
+	}
+	return 0
+}
+
+func (x *Phase) GetTotalNs() int64 {
+	if x != nil {
+		return x.TotalNs
+	}
+	return 0
+}
+
+func (x *Phase) GetMinNs() int64 {
+	if x != nil {
+		return x.MinNs
+	}
+	return 0
+}
+
+func (x *Phase) GetMaxNs() int64 {
+	if x != nil {
+		return x.MaxNs
+	}
+	return 0
+}
+
+// Непустые подкорзины гистограммы: индексы и количества
+type Histogram struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Indexes       []uint32               `protobuf:"varint,1,rep,packed,name=indexes,proto3" json:"indexes,omitempty"`
+	Counts        []int64                `protobuf:"varint,2,rep,packed,name=counts,proto3" json:"counts,omitempty"`
+	Total         int64                  `protobuf:"varint,3,opt,name=total,proto3" json:"total,omitempty"`
+	Min           int64                  `protobuf:"varint,4,opt,name=min,proto3" json:"min,omitempty"`
+	Max           int64                  `protobuf:"varint,5,opt,name=max,proto3" json:"max,omitempty"`
+	Mean          float64                `protobuf:"fixed64,6,opt,name=mean,proto3" json:"mean,omitempty"`
+	M2            float64                `protobuf:"fixed64,7,opt,name=m2,proto3" json:"m2,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Histogram) Reset() {
+	*x = Histogram{}
+	mi := &file_dist_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Histogram) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Histogram) ProtoMessage() {}
+
+func (x *Histogram) ProtoReflect() protoreflect.Message {
+	mi := &file_dist_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Histogram.ProtoReflect.Descriptor instead.
+func (*Histogram) Descriptor() ([]byte, []int) {
+	return file_dist_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *Histogram) GetIndexes() []uint32 {
+	if x != nil {
+		return x.Indexes
+	}
+	return nil
+}
+
+func (x *Histogram) GetCounts() []int64 {
+	if x != nil {
+		return x.Counts
+	}
+	return nil
+}
+
+func (x *Histogram) GetTotal() int64 {
+	if x != nil {
+		return x.Total
+	}
+	return 0
+}
+
+func (x *Histogram) GetMin() int64 {
+	if x != nil {
+		return x.Min
+	}
+	return 0
+}
+
+func (x *Histogram) GetMax() int64 {
+	if x != nil {
+		return x.Max
+	}
+	return 0
+}
+
+func (x *Histogram) GetMean() float64 {
+	if x != nil {
+		return x.Mean
+	}
+	return 0
+}
+
+func (x *Histogram) GetM2() float64 {
+	if x != nil {
+		return x.M2
+	}
+	return 0
+}
+
+type Second struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Requests      int64                  `protobuf:"varint,1,opt,name=requests,proto3" json:"requests,omitempty"`
+	Errors        int64                  `protobuf:"varint,2,opt,name=errors,proto3" json:"errors,omitempty"`
+	TotalNs       int64                  `protobuf:"varint,3,opt,name=total_ns,json=totalNs,proto3" json:"total_ns,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Second) Reset() {
+	*x = Second{}
+	mi := &file_dist_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Second) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Second) ProtoMessage() {}
+
+func (x *Second) ProtoReflect() protoreflect.Message {
+	mi := &file_dist_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Second.ProtoReflect.Descriptor instead.
+func (*Second) Descriptor() ([]byte, []int) {
+	return file_dist_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *Second) GetRequests() int64 {
+	if x != nil {
+		return x.Requests
+	}
+	return 0
+}
+
+func (x *Second) GetErrors() int64 {
+	if x != nil {
+		return x.Errors
+	}
+	return 0
+}
+
+func (x *Second) GetTotalNs() int64 {
+	if x != nil {
+		return x.TotalNs
+	}
+	return 0
+}
+
+type URLCount struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Url           string                 `protobuf:"bytes,1,opt,name=url,proto3" json:"url,omitempty"`
+	TotalRequests int64                  `protobuf:"varint,2,opt,name=total_requests,json=totalRequests,proto3" json:"total_requests,omitempty"`
+	SuccessCount  int64                  `protobuf:"varint,3,opt,name=success_count,json=successCount,proto3" json:"success_count,omitempty"`
+	FailedCount   int64                  `protobuf:"varint,4,opt,name=failed_count,json=failedCount,proto3" json:"failed_count,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *URLCount) Reset() {
+	*x = URLCount{}
+	mi := &file_dist_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *URLCount) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*URLCount) ProtoMessage() {}
+
+func (x *URLCount) ProtoReflect() protoreflect.Message {
+	mi := &file_dist_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use URLCount.ProtoReflect.Descriptor instead.
+func (*URLCount) Descriptor() ([]byte, []int) {
+	return file_dist_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *URLCount) GetUrl() string {
+	if x != nil {
+		return x.Url
+	}
+	return ""
+}
+
+func (x *URLCount) GetTotalRequests() int64 {
+	if x != nil {
+		return x.TotalRequests
+	}
+	return 0
+}
+
+func (x *URLCount) GetSuccessCount() int64 {
+	if x != nil {
+		return x.SuccessCount
+	}
+	return 0
+}
+
+func (x *URLCount) GetFailedCount() int64 {
+	if x != nil {
+		return x.FailedCount
+	}
+	return 0
+}
+
+var File_dist_proto protoreflect.FileDescriptor
+
+const file_dist_proto_rawDesc = "" +
+	"\n" +
+	"\n" +
+	"dist.proto\x12\x12gohttptest.dist.v1\"\x8a\x01\n" +
+	"\n" +
+	"RunRequest\x12\x1f\n" +
+	"\vconfig_yaml\x18\x01 \x01(\fR\n" +
+	"configYaml\x12 \n" +
+	"\vconcurrency\x18\x02 \x01(\x05R\vconcurrency\x12\x1a\n" +
+	"\brequests\x18\x03 \x01(\x03R\brequests\x12\x1d\n" +
+	"\n" +
+	"rate_limit\x18\x04 \x01(\x01R\trateLimit\"\x9d\x02\n" +
+	"\vRunResponse\x12/\n" +
+	"\x05stats\x18\x01 \x01(\v2\x19.gohttptest.dist.v1.StatsR\x05stats\x12*\n" +
+	"\x11total_duration_ns\x18\x02 \x01(\x03R\x0ftotalDurationNs\x12,\n" +
+	"\x12warmup_duration_ns\x18\x03 \x01(\x03R\x10warmupDurationNs\x12'\n" +
+	"\x0fnew_connections\x18\x04 \x01(\x03R\x0enewConnections\x12!\n" +
+	"\fconn_tracked\x18\x05 \x01(\bR\vconnTracked\x12!\n" +
+	"\fabort_reason\x18\x06 \x01(\tR\vabortReason\x12\x14\n" +
+	"\x05error\x18\a \x01(\tR\x05error\"\x8f\f\n" +
+	"\x05Stats\x12%\n" +
+	"\x0etotal_requests\x18\x01 \x01(\x03R\rtotalRequests\x12#\n" +
+	"\rsuccess_count\x18\x02 \x01(\x03R\fsuccessCount\x12!\n" +
+	"\ffailed_count\x18\x03 \x01(\x03R\vfailedCount\x12*\n" +
+	"\x11total_duration_ns\x18\x04 \x01(\x03R\x0ftotalDurationNs\x12\x1f\n" +
+	"\vtotal_bytes\x18\x05 \x01(\x03R\n" +
+	"totalBytes\x12\x1d\n" +
+	"\n" +
+	"total_sent\x18\x06 \x01(\x03R\ttotalSent\x12%\n" +
+	"\x0enetwork_errors\x18\a \x01(\x03R\rnetworkErrors\x12\x18\n" +
+	"\aretries\x18\b \x01(\x03R\aretries\x12\x1c\n" +
+	"\tredirects\x18\t \x01(\x03R\tredirects\x12\x1e\n" +
+	"\n" +
+	"compressed\x18\n" +
+	" \x01(\x03R\n" +
+	"compressed\x12\x1b\n" +
+	"\tzip_bytes\x18\v \x01(\x03R\bzipBytes\x12\x1f\n" +
+	"\vunzip_bytes\x18\f \x01(\x03R\n" +
+	"unzipBytes\x12#\n" +
+	"\rmax_redirects\x18\r \x01(\x03R\fmaxRedirects\x12#\n" +
+	"\rstatus_errors\x18\x0e \x01(\x03R\fstatusErrors\x12#\n" +
+	"\rvia_responses\x18\x0f \x01(\x03R\fviaResponses\x12#\n" +
+	"\rlength_errors\x18\x10 \x01(\x03R\flengthErrors\x12\x1f\n" +
+	"\vbody_errors\x18\x11 \x01(\x03R\n" +
+	"bodyErrors\x12\x1c\n" +
+	"\tcorrected\x18\x12 \x01(\x03R\tcorrected\x12\x1c\n" +
+	"\tsatisfied\x18\x13 \x01(\x03R\tsatisfied\x12\x1e\n" +
+	"\n" +
+	"tolerating\x18\x14 \x01(\x03R\n" +
+	"tolerating\x12M\n" +
+	"\fstatus_codes\x18\x15 \x03(\v2*.gohttptest.dist.v1.Stats.StatusCodesEntryR\vstatusCodes\x12C\n" +
+	"\btimeouts\x18\x16 \x03(\v2'.gohttptest.dist.v1.Stats.TimeoutsEntryR\btimeouts\x12P\n" +
+	"\rheader_errors\x18\x17 \x03(\v2+.gohttptest.dist.v1.Stats.HeaderErrorsEntryR\fheaderErrors\x12F\n" +
+	"\tprotocols\x18\x18 \x03(\v2(.gohttptest.dist.v1.Stats.ProtocolsEntryR\tprotocols\x12+\n" +
+	"\x03dns\x18\x19 \x01(\v2\x19.gohttptest.dist.v1.PhaseR\x03dns\x12+\n" +
+	"\x03tls\x18\x1a \x01(\v2\x19.gohttptest.dist.v1.PhaseR\x03tls\x12;\n" +
+	"\tdurations\x18\x1b \x01(\v2\x1d.gohttptest.dist.v1.HistogramR\tdurations\x121\n" +
+	"\x04ttfb\x18\x1c \x01(\v2\x1d.gohttptest.dist.v1.HistogramR\x04ttfb\x122\n" +
+	"\x06series\x18\x1d \x03(\v2\x1a.gohttptest.dist.v1.SecondR\x06series\x125\n" +
+	"\aper_url\x18\x1e \x03(\v2\x1c.gohttptest.dist.v1.URLCountR\x06perUrl\x1a>\n" +
+	"\x10StatusCodesEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\x05R\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\x03R\x05value:\x028\x01\x1a;\n" +
+	"\rTimeoutsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\x05R\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\x03R\x05value:\x028\x01\x1a?\n" +
+	"\x11HeaderErrorsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\x03R\x05value:\x028\x01\x1a<\n" +
+	"\x0eProtocolsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\x03R\x05value:\x028\x01\"f\n" +
+	"\x05Phase\x12\x14\n" +
+	"\x05count\x18\x01 \x01(\x03R\x05count\x12\x19\n" +
+	"\btotal_ns\x18\x02 \x01(\x03R\atotalNs\x12\x15\n" +
+	"\x06min_ns\x18\x03 \x01(\x03R\x05minNs\x12\x15\n" +
+	"\x06max_ns\x18\x04 \x01(\x03R\x05maxNs\"\x9b\x01\n" +
+	"\tHistogram\x12\x18\n" +
+	"\aindexes\x18\x01 \x03(\rR\aindexes\x12\x16\n" +
+	"\x06counts\x18\x02 \x03(\x03R\x06counts\x12\x14\n" +
+	"\x05total\x18\x03 \x01(\x03R\x05total\x12\x10\n" +
+	"\x03min\x18\x04 \x01(\x03R\x03min\x12\x10\n" +
+	"\x03max\x18\x05 \x01(\x03R\x03max\x12\x12\n" +
+	"\x04mean\x18\x06 \x01(\x01R\x04mean\x12\x0e\n" +
+	"\x02m2\x18\a \x01(\x01R\x02m2\"W\n" +
+	"\x06Second\x12\x1a\n" +
+	"\brequests\x18\x01 \x01(\x03R\brequests\x12\x16\n" +
+	"\x06errors\x18\x02 \x01(\x03R\x06errors\x12\x19\n" +
+	"\btotal_ns\x18\x03 \x01(\x03R\atotalNs\"\x8b\x01\n" +
+	"\bURLCount\x12\x10\n" +
+	"\x03url\x18\x01 \x01(\tR\x03url\x12%\n" +
+	"\x0etotal_requests\x18\x02 \x01(\x03R\rtotalRequests\x12#\n" +
+	"\rsuccess_count\x18\x03 \x01(\x03R\fsuccessCount\x12!\n" +
+	"\ffailed_count\x18\x04 \x01(\x03R\vfailedCount2P\n" +
+	"\x06Worker\x12F\n" +
+	"\x03Run\x12\x1e.gohttptest.dist.v1.RunRequest\x1a\x1f.gohttptest.dist.v1.RunResponseB1Z/github.com/batman565/gohttptest/internal/distpbb\x06proto3"
+
+var (
+	file_dist_proto_rawDescOnce sync.Once
+	file_dist_proto_rawDescData []byte
+)
+
+func file_dist_proto_rawDescGZIP() []byte {
+	file_dist_proto_rawDescOnce.Do(func() {
+		file_dist_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_dist_proto_rawDesc), len(file_dist_proto_rawDesc)))
+	})
+	return file_dist_proto_rawDescData
+}
+
+var file_dist_proto_msgTypes = make([]protoimpl.MessageInfo, 11)
+var file_dist_proto_goTypes = []any{
+	(*RunRequest)(nil),  // 0: gohttptest.dist.v1.RunRequest
+	(*RunResponse)(nil), // 1: gohttptest.dist.v1.RunResponse
+	(*Stats)(nil),       // 2: gohttptest.dist.v1.Stats
+	(*Phase)(nil),       // 3: gohttptest.dist.v1.Phase
+	(*Histogram)(nil),   // 4: gohttptest.dist.v1.Histogram
+	(*Second)(nil),      // 5: gohttptest.dist.v1.Second
+	(*URLCount)(nil),    // 6: gohttptest.dist.v1.URLCount
+	nil,                 // 7: gohttptest.dist.v1.Stats.StatusCodesEntry
+	nil,                 // 8: gohttptest.dist.v1.Stats.TimeoutsEntry
+	nil,                 // 9: gohttptest.dist.v1.Stats.HeaderErrorsEntry
+	nil,                 // 10: gohttptest.dist.v1.Stats.ProtocolsEntry
+}
+var file_dist_proto_depIdxs = []int32{
+	2,  // 0: gohttptest.dist.v1.RunResponse.stats:type_name -> gohttptest.dist.v1.Stats
+	7,  // 1: gohttptest.dist.v1.Stats.status_codes:type_name -> gohttptest.dist.v1.Stats.StatusCodesEntry
+	8,  // 2: gohttptest.dist.v1.Stats.timeouts:type_name -> gohttptest.dist.v1.Stats.TimeoutsEntry
+	9,  // 3: gohttptest.dist.v1.Stats.header_errors:type_name -> gohttptest.dist.v1.Stats.HeaderErrorsEntry
+	10, // 4: gohttptest.dist.v1.Stats.protocols:type_name -> gohttptest.dist.v1.Stats.ProtocolsEntry
+	3,  // 5: gohttptest.dist.v1.Stats.dns:type_name -> gohttptest.dist.v1.Phase
+	3,  // 6: gohttptest.dist.v1.Stats.tls:type_name -> gohttptest.dist.v1.Phase
+	4,  // 7: gohttptest.dist.v1.Stats.durations:type_name -> gohttptest.dist.v1.Histogram
+	4,  // 8: gohttptest.dist.v1.Stats.ttfb:type_name -> gohttptest.dist.v1.Histogram
+	5,  // 9: gohttptest.dist.v1.Stats.series:type_name -> gohttptest.dist.v1.Second
+	6,  // 10: gohttptest.dist.v1.Stats.per_url:type_name -> gohttptest.dist.v1.URLCount
+	0,  // 11: gohttptest.dist.v1.Worker.Run:input_type -> gohttptest.dist.v1.RunRequest
+	1,  // 12: gohttptest.dist.v1.Worker.Run:output_type -> gohttptest.dist.v1.RunResponse
+	12, // [12:13] is the sub-list for method output_type
+	11, // [11:12] is the sub-list for method input_type
+	11, // [11:11] is the sub-list for extension type_name
+	11, // [11:11] is the sub-list for extension extendee
+	0,  // [0:11] is the sub-list for field type_name
+}
+
+func init() { file_dist_proto_init() }
+func file_dist_proto_init() {
+	if File_dist_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_dist_proto_rawDesc), len(file_dist_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   11,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_dist_proto_goTypes,
+		DependencyIndexes: file_dist_proto_depIdxs,
+		MessageInfos:      file_dist_proto_msgTypes,
+	}.Build()
+	File_dist_proto = out.File
+	file_dist_proto_goTypes = nil
+	file_dist_proto_depIdxs = nil
+}
@@ -0,0 +1,4 @@
+// Типы и сервис протокола распределенного режима, сгенерированы из dist.proto
+package distpb
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative dist.proto
@@ -0,0 +1,322 @@
+package gohttptest
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"math"
+	"math/rand"
+	"os"
+	"slices"
+	"strings"
+)
+
+// Максимальная вложенность генерируемого документа, защищает от
+// рекурсивных $ref
+const maxSchemaDepth = 10
+
+// Документ не помещается в maxSchemaDepth: обязательные свойства
+// ссылаются друг на друга без выхода из рекурсии
+var errSchemaDepth = fmt.Errorf("schema nesting exceeds %d levels", maxSchemaDepth)
+
+// Подмножество JSON Schema draft-07 для генерации тел запросов
+type jsonSchema struct {
+	Ref        string                 `json:"$ref"`
+	Type       schemaType             `json:"type"`
+	Properties map[string]*jsonSchema `json:"properties"`
+	Required   []string               `json:"required"`
+	Items      *jsonSchema            `json:"items"`
+	MinItems   *int                   `json:"minItems"`
+	MaxItems   *int                   `json:"maxItems"`
+	Minimum    *float64               `json:"minimum"`
+	Maximum    *float64               `json:"maximum"`
+	MinLength  *int                   `json:"minLength"`
+	MaxLength  *int                   `json:"maxLength"`
+	Enum       []any                  `json:"enum"`
+	Const      any                    `json:"const"`
+
+	Definitions map[string]*jsonSchema `json:"definitions"`
+	Defs        map[string]*jsonSchema `json:"$defs"`
+}
+
+// Тип схемы: строка или массив строк ("type": ["string", "null"])
+type schemaType []string
+
+func (t *schemaType) UnmarshalJSON(data []byte) error {
+	var one string
+	if err := json.Unmarshal(data, &one); err == nil {
+		*t = schemaType{one}
+		return nil
+	}
+	var many []string
+	if err := json.Unmarshal(data, &many); err != nil {
+		return err
+	}
+	*t = many
+	return nil
+}
+
+// Читает JSON Schema и проверяет, что все $ref разрешаются, числовые
+// границы выполнимы, а документ помещается в maxSchemaDepth
+func readJSONSchema(path string) (*jsonSchema, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var root jsonSchema
+	if err := json.Unmarshal(data, &root); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+	if err := root.check(&root, 0); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	// Необязательные свойства при генерации пропускаются, обязательные
+	// превышают глубину в любом документе, поэтому одной попытки достаточно
+	if _, err := root.generate(rand.New(rand.NewSource(1))); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return &root, nil
+}
+
+func (s *jsonSchema) check(root *jsonSchema, depth int) error {
+	if s == nil || depth > maxSchemaDepth {
+		return nil
+	}
+	if s.Ref != "" {
+		if _, err := root.resolve(s.Ref); err != nil {
+			return err
+		}
+	}
+	if s.Minimum != nil && s.Maximum != nil && *s.Minimum > *s.Maximum {
+		return fmt.Errorf("minimum %v is greater than maximum %v", *s.Minimum, *s.Maximum)
+	}
+	if s.Const == nil && len(s.Enum) == 0 && s.typeName() == "integer" {
+		if _, _, ok := s.intRange(); !ok {
+			lo, hi := s.numberRange()
+			return fmt.Errorf("no integer in range [%v, %v]", lo, hi)
+		}
+	}
+	for _, p := range s.Properties {
+		if err := p.check(root, depth+1); err != nil {
+			return err
+		}
+	}
+	for _, d := range s.Definitions {
+		if err := d.check(root, depth+1); err != nil {
+			return err
+		}
+	}
+	for _, d := range s.Defs {
+		if err := d.check(root, depth+1); err != nil {
+			return err
+		}
+	}
+	return s.Items.check(root, depth+1)
+}
+
+// Схема по ссылке "#", "#/definitions/<name>" или "#/$defs/<name>"
+func (s *jsonSchema) resolve(ref string) (*jsonSchema, error) {
+	if ref == "#" {
+		return s, nil
+	}
+	var defs map[string]*jsonSchema
+	name, ok := strings.CutPrefix(ref, "#/definitions/")
+	if ok {
+		defs = s.Definitions
+	} else if name, ok = strings.CutPrefix(ref, "#/$defs/"); ok {
+		defs = s.Defs
+	} else {
+		return nil, fmt.Errorf("unsupported $ref %q", ref)
+	}
+	d, ok := defs[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown $ref %q", ref)
+	}
+	return d, nil
+}
+
+// Генерирует случайный JSON документ, соответствующий схеме
+func (s *jsonSchema) generate(rnd *rand.Rand) ([]byte, error) {
+	v, err := s.value(s, rnd, 0)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(v)
+}
+
+func (s *jsonSchema) value(root *jsonSchema, rnd *rand.Rand, depth int) (any, error) {
+	if depth > maxSchemaDepth {
+		if s.nullable(root) {
+			return nil, nil
+		}
+		return nil, errSchemaDepth
+	}
+	if s.Ref != "" {
+		// Ссылки проверены при чтении схемы
+		ref, _ := root.resolve(s.Ref)
+		return ref.value(root, rnd, depth+1)
+	}
+	if s.Const != nil {
+		return s.Const, nil
+	}
+	if len(s.Enum) > 0 {
+		return s.Enum[rnd.Intn(len(s.Enum))], nil
+	}
+
+	switch s.typeName() {
+	case "object":
+		obj := make(map[string]any, len(s.Properties))
+		for _, name := range slices.Sorted(maps.Keys(s.Properties)) {
+			// Необязательные свойства добавляются через раз, чтобы документы
+			// отличались составом полей
+			required := slices.Contains(s.Required, name)
+			if !required && rnd.Intn(2) == 0 {
+				continue
+			}
+			v, err := s.Properties[name].value(root, rnd, depth+1)
+			if errors.Is(err, errSchemaDepth) && !required {
+				continue
+			}
+			if err != nil {
+				return nil, err
+			}
+			obj[name] = v
+		}
+		return obj, nil
+	case "array":
+		lo, hi := rangeOr(s.MinItems, s.MaxItems, 1, 3)
+		arr := make([]any, lo+rnd.Intn(hi-lo+1))
+		if s.Items != nil {
+			for i := range arr {
+				v, err := s.Items.value(root, rnd, depth+1)
+				// Рекурсия через элементы массива заканчивается пустым
+				// массивом, если схема его допускает
+				if errors.Is(err, errSchemaDepth) && (s.MinItems == nil || *s.MinItems == 0) {
+					return []any{}, nil
+				}
+				if err != nil {
+					return nil, err
+				}
+				arr[i] = v
+			}
+		}
+		return arr, nil
+	case "integer":
+		lo, hi, ok := s.intRange()
+		if !ok {
+			lo, hi := s.numberRange()
+			return nil, fmt.Errorf("no integer in range [%v, %v]", lo, hi)
+		}
+		span := uint64(hi - lo)
+		if span < math.MaxInt64 {
+			return lo + rnd.Int63n(int64(span)+1), nil
+		}
+		// Диапазон шире int64: выборка с отбрасыванием, каждая попытка
+		// удачна с вероятностью не меньше половины
+		for {
+			if n := rnd.Uint64(); n <= span {
+				return lo + int64(n), nil
+			}
+		}
+	case "number":
+		lo, hi := s.numberRange()
+		return lo + rnd.Float64()*(hi-lo), nil
+	case "boolean":
+		return rnd.Intn(2) == 0, nil
+	case "null":
+		return nil, nil
+	}
+	lo, hi := rangeOr(s.MinLength, s.MaxLength, 1, 16)
+	return randomString(rnd, lo+rnd.Intn(hi-lo+1)), nil
+}
+
+// Допускает ли схема null, с учетом $ref. Цепочка ссылок ограничена
+// maxSchemaDepth, как и при генерации
+func (s *jsonSchema) nullable(root *jsonSchema) bool {
+	for range maxSchemaDepth {
+		if s.Ref == "" {
+			break
+		}
+		s, _ = root.resolve(s.Ref)
+	}
+	return slices.Contains(s.Type, "null")
+}
+
+// Первый тип схемы, кроме null. Без type схема со свойствами - объект
+func (s *jsonSchema) typeName() string {
+	for _, t := range s.Type {
+		if t != "null" {
+			return t
+		}
+	}
+	if s.Properties != nil {
+		return "object"
+	}
+	return ""
+}
+
+// Целочисленные границы numberRange, ограниченные диапазоном int64.
+// false, если между границами нет ни одного целого
+func (s *jsonSchema) intRange() (int64, int64, bool) {
+	lo, hi := s.numberRange()
+	lo, hi = math.Ceil(lo), math.Floor(hi)
+	if hi < lo || hi < math.MinInt64 || lo >= math.MaxInt64 {
+		return 0, 0, false
+	}
+	return clampInt64(lo), clampInt64(hi), true
+}
+
+// Преобразует целое число float64 в int64 с насыщением: float64(MaxInt64)
+// равно 2^63 и при прямом преобразовании переполняется
+func clampInt64(f float64) int64 {
+	switch {
+	case f >= math.MaxInt64:
+		return math.MaxInt64
+	case f <= math.MinInt64:
+		return math.MinInt64
+	}
+	return int64(f)
+}
+
+// Границы числа, по умолчанию [0, 1000]
+func (s *jsonSchema) numberRange() (float64, float64) {
+	lo, hi := 0.0, 1000.0
+	if s.Minimum != nil {
+		lo = *s.Minimum
+		if s.Maximum == nil {
+			hi = lo + 1000
+		}
+	}
+	if s.Maximum != nil {
+		hi = *s.Maximum
+		if s.Minimum == nil {
+			lo = min(0, hi)
+		}
+	}
+	return lo, max(lo, hi)
+}
+
+// Границы длины: заданные значения или значения по умолчанию
+func rangeOr(minV, maxV *int, defMin, defMax int) (int, int) {
+	lo, hi := defMin, defMax
+	if minV != nil {
+		lo = *minV
+		hi = max(hi, lo)
+	}
+	if maxV != nil {
+		hi = *maxV
+		lo = min(lo, hi)
+	}
+	return lo, hi
+}
+
+const randomLetters = "abcdefghijklmnopqrstuvwxyz"
+
+func randomString(rnd *rand.Rand, n int) string {
+	b := make([]byte, n)
+	for i := range b {
+		b[i] = randomLetters[rnd.Intn(len(randomLetters))]
+	}
+	return string(b)
+}
@@ -0,0 +1,113 @@
+package gohttptest
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func loadSchema(t *testing.T, schema string) (*jsonSchema, error) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "schema.json")
+	if err := os.WriteFile(path, []byte(schema), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return readJSONSchema(path)
+}
+
+// Границы за пределами int64 ограничиваются им, без переполнения
+func TestJSONSchemaIntegerBounds(t *testing.T) {
+	tests := []struct {
+		name   string
+		schema string
+		lo, hi int64
+	}{
+		{"wider than int64", `{"type": "integer", "minimum": -1e300, "maximum": 1e300}`, math.MinInt64, math.MaxInt64},
+		{"above int64", `{"type": "integer", "minimum": 9.2e18, "maximum": 1e19}`, 9.2e18, math.MaxInt64},
+		{"below int64", `{"type": "integer", "minimum": -1e19, "maximum": -9.2e18}`, math.MinInt64, -9.2e18},
+		{"fractional", `{"type": "integer", "minimum": 1.5, "maximum": 2.5}`, 2, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := loadSchema(t, tt.schema)
+			if err != nil {
+				t.Fatalf("readJSONSchema: %v", err)
+			}
+			rnd := rand.New(rand.NewSource(1))
+			for range 100 {
+				v, err := s.value(s, rnd, 0)
+				if err != nil {
+					t.Fatalf("value: %v", err)
+				}
+				n, ok := v.(int64)
+				if !ok || n < tt.lo || n > tt.hi {
+					t.Fatalf("value = %v (%T), want int64 in [%d, %d]", v, v, tt.lo, tt.hi)
+				}
+			}
+		})
+	}
+}
+
+func TestJSONSchemaUnsatisfiable(t *testing.T) {
+	tests := []struct {
+		name, schema, want string
+	}{
+		{"no integer", `{"type": "integer", "minimum": 1.5, "maximum": 1.7}`, "no integer in range"},
+		{"beyond int64", `{"type": "integer", "minimum": 1e19, "maximum": 2e19}`, "no integer in range"},
+		{"nested", `{"properties": {"n": {"type": "integer", "minimum": 0.1, "maximum": 0.9}}}`, "no integer in range"},
+		{"minimum above maximum", `{"type": "number", "minimum": 5, "maximum": 1}`, "greater than maximum"},
+		{"required recursion", `{"type": "object", "properties": {"next": {"$ref": "#"}}, "required": ["next"]}`, "nesting exceeds"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := loadSchema(t, tt.schema)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("readJSONSchema error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+// Рекурсивные схемы, из которых можно выйти, генерируются без ошибок:
+// необязательное свойство пропускается, null и пустой массив допустимы
+func TestJSONSchemaRecursion(t *testing.T) {
+	tests := []struct {
+		name, schema string
+	}{
+		{"optional", `{"type": "object", "properties": {"next": {"$ref": "#"}}}`},
+		{"nullable", `{"type": ["object", "null"], "properties": {"next": {"$ref": "#"}}, "required": ["next"]}`},
+		{"array", `{"type": "object", "properties": {"children": {"type": "array", "items": {"$ref": "#"}}}, "required": ["children"]}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := loadSchema(t, tt.schema)
+			if err != nil {
+				t.Fatalf("readJSONSchema: %v", err)
+			}
+			rnd := rand.New(rand.NewSource(1))
+			for range 20 {
+				data, err := s.generate(rnd)
+				if err != nil {
+					t.Fatalf("generate: %v", err)
+				}
+				if !json.Valid(data) {
+					t.Fatalf("invalid JSON: %s", data)
+				}
+			}
+		})
+	}
+}
+
+// Превышение глубины обязательным свойством - ошибка, а не null в документе
+func TestJSONSchemaDepthError(t *testing.T) {
+	s := &jsonSchema{Type: schemaType{"object"}, Required: []string{"next"}}
+	s.Properties = map[string]*jsonSchema{"next": {Ref: "#"}}
+	if _, err := s.generate(rand.New(rand.NewSource(1))); !errors.Is(err, errSchemaDepth) {
+		t.Errorf("generate error = %v, want %v", err, errSchemaDepth)
+	}
+}
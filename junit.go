@@ -0,0 +1,113 @@
+package gohttptest
+
+import (
+	"encoding/xml"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+)
+
+// Результаты в формате JUnit XML: каждый запрос - testcase
+type junitLog struct {
+	names []string
+	start time.Time
+	cases []junitCase
+}
+
+type junitSuite struct {
+	XMLName   xml.Name    `xml:"testsuite"`
+	Name      string      `xml:"name,attr"`
+	Tests     int         `xml:"tests,attr"`
+	Failures  int         `xml:"failures,attr"`
+	Errors    int         `xml:"errors,attr"`
+	Skipped   int         `xml:"skipped,attr"`
+	Time      string      `xml:"time,attr"`
+	Timestamp string      `xml:"timestamp,attr"`
+	Cases     []junitCase `xml:"testcase"`
+}
+
+type junitCase struct {
+	Name      string        `xml:"name,attr"`
+	Classname string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// urls - адреса WithURLs, пусто - единственный адрес site.
+// classname каждого testcase - адрес его запроса
+func newJUnitLog(urls []string, site string, start time.Time) *junitLog {
+	if len(urls) == 0 {
+		urls = []string{site}
+	}
+	names := make([]string, len(urls))
+	for i, u := range urls {
+		names[i] = displayURL(u)
+	}
+	return &junitLog{names: names, start: start}
+}
+
+func (l *junitLog) add(res result, ok bool) {
+	c := junitCase{
+		Name:      fmt.Sprintf("request %d", len(l.cases)+1),
+		Classname: l.names[res.URLIndex],
+		Time:      junitSeconds(res.Duration),
+	}
+	if !ok {
+		msg := fmt.Sprintf("unexpected status %d", res.StatusCode)
+		if res.Error != nil {
+			msg = res.Error.Error()
+		}
+		c.Failure = &junitFailure{
+			Message: msg,
+			Type:    "status " + strconv.Itoa(res.StatusCode),
+			Text:    msg,
+		}
+	}
+	l.cases = append(l.cases, c)
+}
+
+// Записывает файл, атрибуты testsuite берутся из итоговой статистики
+func (l *junitLog) write(path string, r BenchmarkResult) error {
+	name := "gohttptest"
+	switch {
+	case r.Name != "":
+		name = r.Name
+	case len(l.names) == 1:
+		name = l.names[0]
+	}
+	suite := junitSuite{
+		Name:      name,
+		Tests:     r.TotalRequests,
+		Failures:  r.FailedCount,
+		Time:      junitSeconds(r.TotalDuration),
+		Timestamp: l.start.Format("2006-01-02T15:04:05"),
+		Cases:     l.cases,
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if _, err := f.WriteString(xml.Header); err != nil {
+		f.Close()
+		return err
+	}
+	enc := xml.NewEncoder(f)
+	enc.Indent("", "  ")
+	if err := enc.Encode(suite); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func junitSeconds(d time.Duration) string {
+	return strconv.FormatFloat(d.Seconds(), 'f', 6, 64)
+}
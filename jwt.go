@@ -0,0 +1,94 @@
+package gohttptest
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// Пауза перед повтором после неудачного обновления токена
+const jwtRetryInterval = 5 * time.Second
+
+// Текущий JWT токен для всех воркеров и его обновление в фоне
+type jwtRefresher struct {
+	token   atomic.Value
+	refresh func(ctx context.Context) (string, error)
+	before  time.Duration
+}
+
+func newJWTRefresher(token string, refresh func(ctx context.Context) (string, error), before time.Duration) *jwtRefresher {
+	r := &jwtRefresher{refresh: refresh, before: before}
+	r.token.Store(token)
+	return r
+}
+
+func (r *jwtRefresher) current() string {
+	return r.token.Load().(string)
+}
+
+// Обновляет токен за before до его exp, пока не отменен ctx. Ошибки
+// обновления выводятся через warnf, воркеры продолжают со старым токеном
+func (r *jwtRefresher) run(ctx context.Context, warnf func(format string, args ...any)) {
+	var wait time.Duration
+	if exp, err := jwtExpiry(r.current()); err == nil {
+		wait = time.Until(exp.Add(-r.before))
+	}
+	for {
+		timer := time.NewTimer(max(wait, 0))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		token, err := r.refresh(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+		if err != nil {
+			warnf("jwt refresh failed: %v", err)
+			wait = jwtRetryInterval
+			continue
+		}
+		exp, err := jwtExpiry(token)
+		if err != nil {
+			warnf("jwt refresh returned an invalid token: %v", err)
+			wait = jwtRetryInterval
+			continue
+		}
+		r.token.Store(token)
+		wait = time.Until(exp.Add(-r.before))
+		if wait <= 0 {
+			// Иначе обновление шло бы без пауз
+			warnf("refreshed jwt expires within %v", r.before)
+			wait = jwtRetryInterval
+		}
+	}
+}
+
+// Время exp из claims JWT, подпись не проверяется
+func jwtExpiry(token string) (time.Time, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return time.Time{}, errors.New("token is not a JWT")
+	}
+	data, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(parts[1], "="))
+	if err != nil {
+		return time.Time{}, errors.New("claims are not valid base64url")
+	}
+	var claims struct {
+		Exp *float64 `json:"exp"`
+	}
+	if err := json.Unmarshal(data, &claims); err != nil {
+		return time.Time{}, errors.New("claims are not valid JSON")
+	}
+	if claims.Exp == nil {
+		return time.Time{}, errors.New("token has no exp claim")
+	}
+	return time.Unix(int64(*claims.Exp), 0), nil
+}
@@ -0,0 +1,103 @@
+package gohttptest
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"time"
+)
+
+// Границы корзин гистограммы длительности в секундах, как DefBuckets в Prometheus
+var metricsBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+// HTTP сервер с метриками теста в формате Prometheus
+type metricsServer struct {
+	srv *http.Server
+}
+
+// Слушает addr сразу, чтобы ошибка занятого порта вернулась до начала теста
+func startMetrics(addr string, st *stats) (*metricsServer, error) {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/metrics", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+		writeMetrics(w, st)
+	})
+	m := &metricsServer{
+		srv: &http.Server{Handler: mux, ReadHeaderTimeout: 5 * time.Second},
+	}
+	go m.srv.Serve(ln)
+	return m, nil
+}
+
+func (m *metricsServer) close() {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	m.srv.Shutdown(ctx)
+}
+
+// Пишет текущие значения метрик в текстовом формате Prometheus
+func writeMetrics(w io.Writer, st *stats) {
+	fmt.Fprintln(w, "# HELP gohttptest_requests_total Completed requests by status class.")
+	fmt.Fprintln(w, "# TYPE gohttptest_requests_total counter")
+	for class := range st.liveStatus {
+		label := "error"
+		if class > 0 {
+			label = fmt.Sprintf("%dxx", class)
+		}
+		fmt.Fprintf(w, "gohttptest_requests_total{status=%q} %d\n", label, st.liveStatus[class].Load())
+	}
+
+	writeDurationHistogram(w, st)
+
+	fmt.Fprintln(w, "# HELP gohttptest_workers_active Running workers.")
+	fmt.Fprintln(w, "# TYPE gohttptest_workers_active gauge")
+	fmt.Fprintf(w, "gohttptest_workers_active %d\n", st.liveWorkers.Load())
+}
+
+// Пишет гистограмму длительности запросов с корзинами metricsBuckets
+func writeDurationHistogram(w io.Writer, st *stats) {
+	st.mu.Lock()
+	buckets := make([]int64, len(metricsBuckets))
+	for i, b := range metricsBuckets {
+		buckets[i] = st.hist.countAtOrBelow(time.Duration(b * float64(time.Second)))
+	}
+	// Сумма и количество из одной гистограммы, чтобы _sum/_count совпадало
+	// с корзинами и при поправке на coordinated omission
+	count, sum := st.hist.count(), st.hist.sum()
+	st.mu.Unlock()
+
+	fmt.Fprintln(w, "# HELP gohttptest_request_duration_seconds Request duration.")
+	fmt.Fprintln(w, "# TYPE gohttptest_request_duration_seconds histogram")
+	for i, b := range metricsBuckets {
+		fmt.Fprintf(w, "gohttptest_request_duration_seconds_bucket{le=\"%g\"} %d\n", b, buckets[i])
+	}
+	fmt.Fprintf(w, "gohttptest_request_duration_seconds_bucket{le=\"+Inf\"} %d\n", count)
+	fmt.Fprintf(w, "gohttptest_request_duration_seconds_sum %g\n", sum.Seconds())
+	fmt.Fprintf(w, "gohttptest_request_duration_seconds_count %d\n", count)
+}
+
+// Записывает итоговую гистограмму длительности в файл в текстовом формате
+// Prometheus
+func writeHistogramFile(path string, st *stats) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	// bufio.Writer запоминает первую ошибку записи и вернет ее в Flush
+	w := bufio.NewWriter(f)
+	writeDurationHistogram(w, st)
+	if err := w.Flush(); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,59 @@
+package gohttptest
+
+import (
+	"bytes"
+	"os"
+	"regexp"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// _sum и _count с поправкой на coordinated omission считаются по тем же
+// значениям, что и корзины
+func TestDurationHistogramSumMatchesCorrectedCount(t *testing.T) {
+	st := &stats{hist: newHistogram()}
+	// 100ms при интервале 10ms: исходное значение и 9 добавленных 90..10ms
+	if added := st.hist.recordCorrected(100*time.Millisecond, 10*time.Millisecond); added != 9 {
+		t.Fatalf("recordCorrected added %d samples, want 9", added)
+	}
+
+	var buf bytes.Buffer
+	writeDurationHistogram(&buf, st)
+	out := buf.String()
+
+	sum := metricValue(t, out, "gohttptest_request_duration_seconds_sum")
+	count := metricValue(t, out, "gohttptest_request_duration_seconds_count")
+	inf := metricValue(t, out, `gohttptest_request_duration_seconds_bucket{le="+Inf"}`)
+	if count != 10 || inf != count {
+		t.Errorf("count = %v, +Inf bucket = %v, want 10", count, inf)
+	}
+	if mean := sum / count; mean < 0.0549 || mean > 0.0551 {
+		t.Errorf("_sum/_count = %vs, want mean of recorded samples 0.055s", mean)
+	}
+}
+
+func metricValue(t *testing.T, out, name string) float64 {
+	t.Helper()
+	m := regexp.MustCompile(`(?m)^` + regexp.QuoteMeta(name) + ` (\S+)$`).FindStringSubmatch(out)
+	if m == nil {
+		t.Fatalf("no %s in:\n%s", name, out)
+	}
+	v, err := strconv.ParseFloat(m[1], 64)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return v
+}
+
+// Ошибка записи, например нехватка места, возвращается, а не теряется
+func TestHistogramFileWriteError(t *testing.T) {
+	if _, err := os.Stat("/dev/full"); err != nil {
+		t.Skip("/dev/full is not available")
+	}
+	st := &stats{hist: newHistogram()}
+	st.hist.record(time.Millisecond)
+	if err := writeHistogramFile("/dev/full", st); err == nil {
+		t.Error("writeHistogramFile to /dev/full returned nil")
+	}
+}
@@ -0,0 +1,89 @@
+package gohttptest
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+)
+
+// Тестовый HTTP сервер, запоминающий все входящие запросы, для проверки
+// нагрузки без внешнего сервера:
+//
+//	srv := gohttptest.NewMockServer(http.HandlerFunc(...))
+//	defer srv.Close()
+//	result, _ := gohttptest.Test(srv.URL(), 10, 100)
+//
+// Запросы хранятся в памяти вместе с телом, для долгих тестов он не подходит
+type MockServer struct {
+	srv *httptest.Server
+
+	mu       sync.Mutex
+	requests []*http.Request
+}
+
+// Запускает сервер с handler. nil handler отвечает 200 с пустым телом
+func NewMockServer(handler http.Handler) *MockServer {
+	if handler == nil {
+		handler = http.HandlerFunc(func(http.ResponseWriter, *http.Request) {})
+	}
+	m := &MockServer{}
+	m.srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		m.record(r)
+		handler.ServeHTTP(w, r)
+	}))
+	return m
+}
+
+// Сохраняет копию запроса с телом, тело r остается доступным для handler
+func (m *MockServer) record(r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	r.Body.Close()
+	r.Body = io.NopCloser(bytes.NewReader(body))
+
+	saved := r.Clone(context.Background())
+	saved.Body = io.NopCloser(bytes.NewReader(body))
+	saved.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(body)), nil
+	}
+
+	m.mu.Lock()
+	m.requests = append(m.requests, saved)
+	m.mu.Unlock()
+}
+
+// Адрес сервера, например http://127.0.0.1:54321
+func (m *MockServer) URL() string {
+	return m.srv.URL
+}
+
+// Количество полученных запросов
+func (m *MockServer) RequestCount() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.requests)
+}
+
+// Последний полученный запрос, nil если запросов не было
+func (m *MockServer) LastRequest() *http.Request {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if len(m.requests) == 0 {
+		return nil
+	}
+	return m.requests[len(m.requests)-1]
+}
+
+// Все полученные запросы в порядке поступления
+func (m *MockServer) Requests() []*http.Request {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]*http.Request(nil), m.requests...)
+}
+
+// Останавливает сервер и ждет завершения обработки запросов
+func (m *MockServer) Close() {
+	m.srv.Close()
+}
@@ -0,0 +1,136 @@
+package gohttptest
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestMockServerRecordsRequests(t *testing.T) {
+	srv := NewMockServer(nil)
+	defer srv.Close()
+
+	if srv.RequestCount() != 0 || srv.LastRequest() != nil || len(srv.Requests()) != 0 {
+		t.Fatalf("new server has requests: %d", srv.RequestCount())
+	}
+	for _, body := range []string{"first", "second"} {
+		req, err := http.NewRequest(http.MethodPost, srv.URL()+"/path?q="+body, strings.NewReader(body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set("X-Test", body)
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("status = %d, want 200", resp.StatusCode)
+		}
+	}
+
+	if got := srv.RequestCount(); got != 2 {
+		t.Errorf("RequestCount = %d, want 2", got)
+	}
+	last := srv.LastRequest()
+	if last.Method != http.MethodPost || last.URL.Path != "/path" || last.URL.Query().Get("q") != "second" {
+		t.Errorf("LastRequest = %s %s", last.Method, last.URL)
+	}
+	if got := last.Header.Get("X-Test"); got != "second" {
+		t.Errorf("LastRequest header X-Test = %q, want %q", got, "second")
+	}
+	reqs := srv.Requests()
+	if len(reqs) != 2 {
+		t.Fatalf("len(Requests) = %d, want 2", len(reqs))
+	}
+	for i, want := range []string{"first", "second"} {
+		body, err := io.ReadAll(reqs[i].Body)
+		if err != nil || string(body) != want {
+			t.Errorf("request %d body = %q, %v, want %q", i, body, err, want)
+		}
+		// GetBody отдает тело заново после чтения Body
+		rc, err := reqs[i].GetBody()
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, _ = io.ReadAll(rc)
+		if string(body) != want {
+			t.Errorf("request %d GetBody = %q, want %q", i, body, want)
+		}
+	}
+
+	// Requests возвращает копию, изменения не затрагивают сервер
+	reqs[0] = nil
+	if srv.Requests()[0] == nil {
+		t.Error("Requests returned the internal slice")
+	}
+}
+
+// Обработчик видит тело запроса, несмотря на то что сервер его уже прочитал
+func TestMockServerHandlerReadsBody(t *testing.T) {
+	var got string
+	srv := NewMockServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		got = string(b)
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer srv.Close()
+
+	resp, err := http.Post(srv.URL(), "text/plain", strings.NewReader("payload"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated || got != "payload" {
+		t.Errorf("status = %d, handler body = %q", resp.StatusCode, got)
+	}
+}
+
+// Параллельные запросы и чтение записанных запросов во время нагрузки,
+// гонки проверяются go test -race
+func TestMockServerConcurrent(t *testing.T) {
+	const (
+		concurrency = 8
+		requests    = 200
+	)
+	srv := NewMockServer(nil)
+	defer srv.Close()
+
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for {
+			select {
+			case <-done:
+				return
+			default:
+				srv.RequestCount()
+				srv.LastRequest()
+				srv.Requests()
+			}
+		}
+	}()
+
+	res, err := Test(srv.URL(), concurrency, requests,
+		WithMethod(http.MethodPost), WithBody([]byte("body"), "text/plain"), WithProgress(false), WithOutput(io.Discard))
+	close(done)
+	wg.Wait()
+	if err != nil {
+		t.Fatalf("Test: %v", err)
+	}
+	if res.SuccessCount != requests {
+		t.Errorf("SuccessCount = %d, want %d", res.SuccessCount, requests)
+	}
+	if got := srv.RequestCount(); got != requests {
+		t.Errorf("RequestCount = %d, want %d", got, requests)
+	}
+	for i, r := range srv.Requests() {
+		if b, _ := io.ReadAll(r.Body); string(b) != "body" {
+			t.Fatalf("request %d body = %q, want %q", i, b, "body")
+		}
+	}
+}
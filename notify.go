@@ -0,0 +1,131 @@
+package gohttptest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Когда WithSlackWebhook отправляет уведомление, флаги можно объединять
+type NotifyTrigger int
+
+const (
+	// После каждого теста
+	NotifyAlways NotifyTrigger = 1 << iota
+	// Нарушена хотя бы одна проверка WithSLAAssertion
+	NotifySLAFailed
+	// Тест остановлен раньше: прерывание, отмена контекста, порог ошибок
+	NotifyAborted
+)
+
+// Таймаут отправки уведомлений WithSlackWebhook и WithWebhook,
+// переменная для тестов
+var notifyTimeout = 5 * time.Second
+
+// Уведомления, которые еще отправляются
+var notifications sync.WaitGroup
+
+// Ждет отправки уведомлений WithSlackWebhook и WithWebhook всех
+// завершенных тестов. Test не ждет их сам, поэтому программа, которая
+// завершается сразу после Test, должна вызвать WaitNotifications, иначе
+// уведомление может не уйти. Возвращает ctx.Err(), если ctx завершился
+// раньше
+func WaitNotifications(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		notifications.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Нужно ли уведомление для результата bench и ошибки проверки SLA
+func (t NotifyTrigger) matches(bench BenchmarkResult, slaErr error) bool {
+	aborted := bench.AbortReason != StopRequestCount && bench.AbortReason != StopDuration
+	return t&NotifyAlways != 0 ||
+		t&NotifySLAFailed != 0 && slaErr != nil ||
+		t&NotifyAborted != 0 && aborted
+}
+
+// Текст уведомления в формате mrkdwn Slack
+func slackMessage(name string, bench BenchmarkResult, slaErr error) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "*Load test %s*: %s\n", name, bench.AbortReason)
+	fmt.Fprintf(&b, "RPS: %.2f | p99: %v | Success rate: %.2f%%\n",
+		bench.RequestsPerSecond, bench.P99.Round(time.Microsecond), bench.SuccessRate)
+	if slaErr != nil {
+		fmt.Fprintf(&b, "SLA failed: %s", strings.ReplaceAll(slaErr.Error(), "\n", "; "))
+	} else {
+		b.WriteString("SLA: passed")
+	}
+	return b.String()
+}
+
+// Тип тела WithWebhook: JSON или определенный по содержимому
+func webhookContentType(body []byte) string {
+	if json.Valid(body) {
+		return "application/json"
+	}
+	return http.DetectContentType(body)
+}
+
+// Отправляет POST с таймаутом notifyTimeout
+func postWebhook(url, contentType string, body []byte) error {
+	ctx, cancel := context.WithTimeout(context.Background(), notifyTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", contentType)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	return nil
+}
+
+// Отправляет уведомления после теста в фоне. Ошибки отправки только
+// печатаются и не влияют на результат теста, ошибка шаблона WithWebhook
+// возвращается, и тогда этот webhook не вызывается
+func notify(cfg *Config, name string, bench BenchmarkResult, slaErr error) error {
+	if cfg.SlackWebhook != "" && cfg.SlackNotifyOn.matches(bench, slaErr) {
+		body, err := json.Marshal(map[string]string{"text": slackMessage(name, bench, slaErr)})
+		if err != nil {
+			return err
+		}
+		notifications.Add(1)
+		go send(cfg, "slack webhook", cfg.SlackWebhook, "application/json", body)
+	}
+	if cfg.webhookTemplate != nil {
+		var buf bytes.Buffer
+		if err := cfg.webhookTemplate.Execute(&buf, bench); err != nil {
+			return fmt.Errorf("webhook template: %w", err)
+		}
+		body := buf.Bytes()
+		notifications.Add(1)
+		go send(cfg, "webhook", cfg.WebhookURL, webhookContentType(body), body)
+	}
+	return nil
+}
+
+func send(cfg *Config, name, url, contentType string, body []byte) {
+	defer notifications.Done()
+	if err := postWebhook(url, contentType, body); err != nil {
+		fmt.Fprintf(cfg.ErrorOutput, "Warning: %s: %v\n", name, err)
+	}
+}
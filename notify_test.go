@@ -0,0 +1,94 @@
+package gohttptest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Уведомление о нарушении SLA уходит в Slack после WaitNotifications
+func TestSlackWebhookOnSLAFailure(t *testing.T) {
+	srv := NewMockServer(nil)
+	defer srv.Close()
+	slack := NewMockServer(nil)
+	defer slack.Close()
+
+	_, err := Test(srv.URL(), 2, 20, WithOutput(io.Discard), WithName("checkout"),
+		WithSLAAssertion(0.99, time.Nanosecond), WithSlackWebhook(slack.URL(), NotifySLAFailed))
+	if err == nil {
+		t.Fatal("Test passed SLA of 1ns")
+	}
+	if err := WaitNotifications(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	req := slack.LastRequest()
+	if req == nil {
+		t.Fatal("no Slack notification sent")
+	}
+	if ct := req.Header.Get("Content-Type"); ct != "application/json" {
+		t.Errorf("Content-Type = %q", ct)
+	}
+	var payload struct {
+		Text string `json:"text"`
+	}
+	if err := json.NewDecoder(req.Body).Decode(&payload); err != nil {
+		t.Fatalf("decode payload: %v", err)
+	}
+	for _, want := range []string{"checkout", "RPS:", "p99:", "Success rate: 100.00%", "SLA failed: p99"} {
+		if !strings.Contains(payload.Text, want) {
+			t.Errorf("payload %q has no %q", payload.Text, want)
+		}
+	}
+}
+
+// Успешный тест не шлет уведомление с NotifySLAFailed
+func TestSlackWebhookNotTriggered(t *testing.T) {
+	srv := NewMockServer(nil)
+	defer srv.Close()
+	slack := NewMockServer(nil)
+	defer slack.Close()
+
+	if _, err := Test(srv.URL(), 1, 5, WithOutput(io.Discard), WithSlackWebhook(slack.URL(), NotifySLAFailed|NotifyAborted)); err != nil {
+		t.Fatal(err)
+	}
+	WaitNotifications(context.Background())
+	if n := slack.RequestCount(); n != 0 {
+		t.Errorf("got %d notifications for passing test", n)
+	}
+}
+
+// Зависший webhook обрывается по таймауту с предупреждением, а не ошибкой Test
+func TestSlackWebhookTimeout(t *testing.T) {
+	defer func(d time.Duration) { notifyTimeout = d }(notifyTimeout)
+	notifyTimeout = 100 * time.Millisecond
+
+	srv := NewMockServer(nil)
+	defer srv.Close()
+	slack := NewMockServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+	defer slack.Close()
+
+	var errOut bytes.Buffer
+	start := time.Now()
+	_, err := Test(srv.URL(), 1, 5, WithOutput(io.Discard), WithErrorOutput(&errOut),
+		WithSlackWebhook(slack.URL(), NotifyAlways))
+	if err != nil {
+		t.Fatalf("notification failure surfaced as Test error: %v", err)
+	}
+	if err := WaitNotifications(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("notification took %v, timeout not applied", elapsed)
+	}
+	if !strings.Contains(errOut.String(), "Warning: slack webhook:") {
+		t.Errorf("no timeout warning in %q", errOut.String())
+	}
+}
@@ -0,0 +1,240 @@
+package gohttptest
+
+import (
+	"fmt"
+	"maps"
+	"net/url"
+	"os"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Отбор операций для WithOpenAPISpec. Пустые поля не ограничивают
+type OpenAPITestOptions struct {
+	// Только операции хотя бы с одним из тегов
+	IncludeTags []string
+	// Операции с любым из тегов пропускаются
+	ExcludeTags []string
+	// Регулярные выражения для пути из спецификации, например "^/users"
+	IncludePath string
+	ExcludePath string
+}
+
+// Часть OpenAPI 3.0, нужная для построения GET запросов
+type openAPISpec struct {
+	OpenAPI string `yaml:"openapi"`
+	Servers []struct {
+		URL string `yaml:"url"`
+	} `yaml:"servers"`
+	Paths map[string]struct {
+		Parameters []openAPIParameter `yaml:"parameters"`
+		Get        *struct {
+			Tags       []string           `yaml:"tags"`
+			Parameters []openAPIParameter `yaml:"parameters"`
+		} `yaml:"get"`
+	} `yaml:"paths"`
+	Components struct {
+		Parameters map[string]openAPIParameter `yaml:"parameters"`
+		Schemas    map[string]openAPISchema    `yaml:"schemas"`
+	} `yaml:"components"`
+}
+
+type openAPIParameter struct {
+	Ref      string         `yaml:"$ref"`
+	Name     string         `yaml:"name"`
+	In       string         `yaml:"in"`
+	Required bool           `yaml:"required"`
+	Example  any            `yaml:"example"`
+	Schema   *openAPISchema `yaml:"schema"`
+}
+
+type openAPISchema struct {
+	Ref       string   `yaml:"$ref"`
+	Type      string   `yaml:"type"`
+	Format    string   `yaml:"format"`
+	Example   any      `yaml:"example"`
+	Default   any      `yaml:"default"`
+	Enum      []any    `yaml:"enum"`
+	Minimum   *float64 `yaml:"minimum"`
+	MinLength int      `yaml:"minLength"`
+}
+
+// Строит GET запросы для всех операций спецификации (YAML или JSON).
+// Параметры пути и обязательные параметры query и header заполняются
+// значениями example, default, первым значением enum или минимальным
+// значением по типу. Адреса - пути относительно site с префиксом пути
+// из servers[0]
+func readOpenAPISpec(path string, opts OpenAPITestOptions) ([]WeightedURL, error) {
+	var include, exclude *regexp.Regexp
+	var err error
+	if opts.IncludePath != "" {
+		if include, err = regexp.Compile(opts.IncludePath); err != nil {
+			return nil, fmt.Errorf("include path: %w", err)
+		}
+	}
+	if opts.ExcludePath != "" {
+		if exclude, err = regexp.Compile(opts.ExcludePath); err != nil {
+			return nil, fmt.Errorf("exclude path: %w", err)
+		}
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	// JSON - подмножество YAML, один декодер подходит для обоих
+	var spec openAPISpec
+	if err := yaml.Unmarshal(data, &spec); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+	if !strings.HasPrefix(spec.OpenAPI, "3.") {
+		return nil, fmt.Errorf("%s: only OpenAPI 3.x is supported, got version %q", path, spec.OpenAPI)
+	}
+
+	prefix := ""
+	if len(spec.Servers) > 0 {
+		if u, err := url.Parse(spec.Servers[0].URL); err == nil {
+			prefix = strings.TrimRight(u.Path, "/")
+		}
+	}
+
+	var entries []WeightedURL
+	for _, p := range slices.Sorted(maps.Keys(spec.Paths)) {
+		item := spec.Paths[p]
+		op := item.Get
+		if op == nil {
+			continue
+		}
+		if include != nil && !include.MatchString(p) || exclude != nil && exclude.MatchString(p) {
+			continue
+		}
+		if len(opts.IncludeTags) > 0 && !slices.ContainsFunc(op.Tags, func(t string) bool { return slices.Contains(opts.IncludeTags, t) }) {
+			continue
+		}
+		if slices.ContainsFunc(op.Tags, func(t string) bool { return slices.Contains(opts.ExcludeTags, t) }) {
+			continue
+		}
+
+		reqPath := p
+		query := url.Values{}
+		headers := make(map[string]string)
+		// Параметры операции переопределяют параметры пути с тем же именем
+		params := make(map[string]openAPIParameter)
+		for _, param := range append(slices.Clone(item.Parameters), op.Parameters...) {
+			param, err := spec.resolveParameter(param)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", p, err)
+			}
+			params[param.In+":"+param.Name] = param
+		}
+		for _, key := range slices.Sorted(maps.Keys(params)) {
+			param := params[key]
+			if param.In != "path" && !param.Required {
+				continue
+			}
+			value := spec.exampleValue(param)
+			switch param.In {
+			case "path":
+				reqPath = strings.ReplaceAll(reqPath, "{"+param.Name+"}", url.PathEscape(value))
+			case "query":
+				query.Set(param.Name, value)
+			case "header":
+				headers[param.Name] = value
+			}
+		}
+		if strings.Contains(reqPath, "{") {
+			return nil, fmt.Errorf("%s: path parameter is not described", p)
+		}
+
+		target := prefix + reqPath
+		if len(query) > 0 {
+			target += "?" + query.Encode()
+		}
+		e := WeightedURL{URL: target, Weight: 1, Method: "GET"}
+		if len(headers) > 0 {
+			e.Headers = headers
+		}
+		entries = append(entries, e)
+	}
+	if len(entries) == 0 {
+		return nil, fmt.Errorf("%s contains no matching GET operations", path)
+	}
+	return entries, nil
+}
+
+// Подставляет параметр из components/parameters по $ref
+func (s *openAPISpec) resolveParameter(p openAPIParameter) (openAPIParameter, error) {
+	if p.Ref == "" {
+		return p, nil
+	}
+	name, ok := strings.CutPrefix(p.Ref, "#/components/parameters/")
+	if !ok {
+		return p, fmt.Errorf("unsupported $ref %q", p.Ref)
+	}
+	resolved, ok := s.Components.Parameters[name]
+	if !ok {
+		return p, fmt.Errorf("unknown parameter %q", p.Ref)
+	}
+	return resolved, nil
+}
+
+// Значение параметра для запроса
+func (s *openAPISpec) exampleValue(p openAPIParameter) string {
+	if p.Example != nil {
+		return fmt.Sprint(p.Example)
+	}
+	schema := s.resolveSchema(p.Schema)
+	if schema == nil {
+		return "1"
+	}
+	switch {
+	case schema.Example != nil:
+		return fmt.Sprint(schema.Example)
+	case schema.Default != nil:
+		return fmt.Sprint(schema.Default)
+	case len(schema.Enum) > 0:
+		return fmt.Sprint(schema.Enum[0])
+	}
+	switch schema.Type {
+	case "integer", "number":
+		if schema.Minimum != nil {
+			return strconv.FormatFloat(*schema.Minimum, 'f', -1, 64)
+		}
+		return "1"
+	case "boolean":
+		return "true"
+	}
+	switch schema.Format {
+	case "uuid":
+		return "00000000-0000-4000-8000-000000000000"
+	case "date":
+		return "2024-01-01"
+	case "date-time":
+		return "2024-01-01T00:00:00Z"
+	}
+	return strings.Repeat("a", max(schema.MinLength, 1))
+}
+
+// Подставляет схему из components/schemas по $ref, nil если ссылка неизвестна
+func (s *openAPISpec) resolveSchema(schema *openAPISchema) *openAPISchema {
+	// Ограничение глубины на случай циклических ссылок
+	for range 10 {
+		if schema == nil || schema.Ref == "" {
+			return schema
+		}
+		name, ok := strings.CutPrefix(schema.Ref, "#/components/schemas/")
+		if !ok {
+			return nil
+		}
+		resolved, ok := s.Components.Schemas[name]
+		if !ok {
+			return nil
+		}
+		schema = &resolved
+	}
+	return nil
+}
@@ -0,0 +1,148 @@
+package gohttptest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Этап RunPipeline
+type Stage struct {
+	// Имя этапа в отчетах, пусто - "stage N"
+	Name        string
+	Duration    time.Duration
+	Concurrency int
+	// Опции этапа. Настройки транспорта и клиента берутся из первого этапа,
+	// потому что соединения и воркеры переходят между этапами
+	Options []Option
+}
+
+// Транспорт и воркеры, общие для этапов RunPipeline
+type pipelineState struct {
+	transport *http.Transport
+	conns     atomic.Int64
+
+	mu      sync.Mutex
+	workers map[int]*worker
+	// Горутины воркеров: выполняют цикл воркера каждого этапа, в котором
+	// он участвует, и ждут следующего этапа
+	loops map[int]chan func()
+}
+
+// Выполняет цикл воркера этапа в горутине workerID, создает горутину,
+// если воркер с таким номером еще не запускался
+func (p *pipelineState) exec(workerID int, loop func()) {
+	p.mu.Lock()
+	ch, ok := p.loops[workerID]
+	if !ok {
+		ch = make(chan func(), 1)
+		p.loops[workerID] = ch
+		go func() {
+			for loop := range ch {
+				loop()
+			}
+		}()
+	}
+	p.mu.Unlock()
+	// Если воркер еще заканчивает прошлый цикл, новый начнется после него
+	ch <- loop
+}
+
+// Останавливает горутины воркеров после последнего этапа
+func (p *pipelineState) close() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, ch := range p.loops {
+		close(ch)
+	}
+	if p.transport != nil {
+		p.transport.CloseIdleConnections()
+	}
+}
+
+// Воркер workerID с предыдущего этапа или новый. У сохраненного воркера
+// остаются клиент, cookie и WorkerContext, меняются только настройки этапа
+func (p *pipelineState) worker(workerID int, cfg *Config, transport *http.Transport) (*worker, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if w, ok := p.workers[workerID]; ok {
+		w.cfg = cfg
+		return w, false
+	}
+	w := newWorker(workerID, cfg, transport)
+	p.workers[workerID] = w
+	return w, true
+}
+
+func newPipelineState() *pipelineState {
+	return &pipelineState{workers: make(map[int]*worker), loops: make(map[int]chan func())}
+}
+
+// Выполняет этапы по очереди как один тест: соединения, воркеры и их
+// горутины не пересоздаются, между этапами новые горутины запускаются
+// только для добавленных воркеров, лишние ждут следующего этапа.
+// Каждый этап дает свой BenchmarkResult. Ошибка этапа останавливает
+// конвейер, возвращаются результаты выполненных этапов
+func RunPipeline(ctx context.Context, stages []Stage, site string) ([]BenchmarkResult, error) {
+	p := newPipelineState()
+	defer p.close()
+	return p.run(ctx, stages, site)
+}
+
+func (p *pipelineState) run(ctx context.Context, stages []Stage, site string) ([]BenchmarkResult, error) {
+	var (
+		results []BenchmarkResult
+		prev    int
+	)
+	for i, stage := range stages {
+		cfg, err := NewConfig(stage.Options...)
+		if err != nil {
+			return results, fmt.Errorf("stage %d: %w", i+1, err)
+		}
+		if stage.Duration <= 0 || stage.Concurrency <= 0 {
+			return results, fmt.Errorf("stage %d: duration and concurrency must be positive", i+1)
+		}
+		cfg.Name = stage.Name
+		if cfg.Name == "" {
+			cfg.Name = fmt.Sprintf("stage %d", i+1)
+		}
+		cfg.Concurrency = stage.Concurrency
+		cfg.Duration = stage.Duration
+		cfg.Requests = 0
+		if cfg.Context == nil {
+			cfg.Context = ctx
+		}
+		cfg.pipeline = p
+
+		if !cfg.Quiet {
+			out := cfg.Output
+			if out == nil {
+				out = os.Stdout
+			}
+			fmt.Fprintf(out, "=== Stage %d/%d: %s, %v", i+1, len(stages), cfg.Name, stage.Duration)
+			if i > 0 {
+				fmt.Fprintf(out, ", concurrency %d -> %d ===\n\n", prev, stage.Concurrency)
+			} else {
+				fmt.Fprintf(out, ", concurrency %d ===\n\n", stage.Concurrency)
+			}
+		}
+		prev = stage.Concurrency
+
+		r, err := run(*cfg, site)
+		if r.TotalRequests > 0 || err == nil {
+			results = append(results, r)
+		}
+		if err != nil {
+			return results, fmt.Errorf("stage %q: %w", cfg.Name, err)
+		}
+		if r.AbortReason == StopInterrupt || r.AbortReason == StopContext {
+			return results, errors.New("pipeline interrupted")
+		}
+	}
+	return results, nil
+}
@@ -0,0 +1,92 @@
+package gohttptest
+
+import (
+	"context"
+	"io"
+	"os"
+	"os/signal"
+	"runtime"
+	"testing"
+	"time"
+)
+
+// Число горутин до теста. Первый signal.Notify в процессе запускает
+// горутину os/signal, которая живет до конца процесса, поэтому она
+// запускается заранее и не считается утечкой
+func goroutineBaseline() int {
+	ch := make(chan os.Signal, 1)
+	signal.Notify(ch, os.Interrupt)
+	signal.Stop(ch)
+	return runtime.NumGoroutine()
+}
+
+// Ждет, пока горутины, запущенные после goroutineBaseline, завершатся:
+// они останавливаются асинхронно
+func checkGoroutines(t *testing.T, before int) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	n := runtime.NumGoroutine()
+	for n > before && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+		n = runtime.NumGoroutine()
+	}
+	if n > before {
+		buf := make([]byte, 1<<16)
+		t.Errorf("%d goroutines left, %d before test\n%s", n, before, buf[:runtime.Stack(buf, true)])
+	}
+}
+
+// Этапы с уменьшением и ростом конкурентности: воркеры и их горутины
+// переходят между этапами, всего их создается столько, сколько нужно
+// самому большому этапу, после конвейера горутины не остаются
+func TestPipelineReusesWorkers(t *testing.T) {
+	srv := NewMockServer(nil)
+	defer srv.Close()
+	before := goroutineBaseline()
+
+	stages := []Stage{
+		{Duration: 100 * time.Millisecond, Concurrency: 4},
+		{Duration: 100 * time.Millisecond, Concurrency: 2},
+		{Duration: 100 * time.Millisecond, Concurrency: 6},
+	}
+	for i := range stages {
+		stages[i].Options = []Option{WithQuiet(true), WithOutput(io.Discard)}
+	}
+
+	p := newPipelineState()
+	results, err := p.run(context.Background(), stages, srv.URL())
+	if err != nil {
+		t.Fatalf("pipeline: %v", err)
+	}
+	if len(results) != len(stages) {
+		t.Fatalf("got %d results, want %d", len(results), len(stages))
+	}
+	for i, r := range results {
+		if r.TotalRequests == 0 || r.FailedCount != 0 {
+			t.Errorf("stage %d: TotalRequests = %d, FailedCount = %d", i+1, r.TotalRequests, r.FailedCount)
+		}
+	}
+	// 4 воркера первого этапа переиспользуются, третий добавляет только 2
+	if len(p.workers) != 6 || len(p.loops) != 6 {
+		t.Errorf("created %d workers and %d worker goroutines, want 6", len(p.workers), len(p.loops))
+	}
+	p.close()
+
+	checkGoroutines(t, before)
+}
+
+// RunPipeline сам останавливает горутины воркеров
+func TestRunPipelineNoGoroutineLeak(t *testing.T) {
+	srv := NewMockServer(nil)
+	defer srv.Close()
+	before := goroutineBaseline()
+
+	_, err := RunPipeline(context.Background(), []Stage{
+		{Duration: 50 * time.Millisecond, Concurrency: 3, Options: []Option{WithQuiet(true)}},
+		{Duration: 50 * time.Millisecond, Concurrency: 1, Options: []Option{WithQuiet(true)}},
+	}, srv.URL())
+	if err != nil {
+		t.Fatalf("RunPipeline: %v", err)
+	}
+	checkGoroutines(t, before)
+}
@@ -0,0 +1,60 @@
+package gohttptest
+
+import (
+	"os"
+	"runtime/pprof"
+)
+
+// Профилирование самого gohttptest на время теста. Ошибки только
+// печатаются: профиль не должен влиять на результат теста
+type profiler struct {
+	cfg *Config
+	cpu *os.File
+}
+
+// Начинает запись CPU профиля, если задан WithCPUProfile
+func startProfiler(cfg *Config) *profiler {
+	p := &profiler{cfg: cfg}
+	if cfg.CPUProfile == "" {
+		return p
+	}
+	f, err := os.Create(cfg.CPUProfile)
+	if err != nil {
+		cfg.warnf("cpu profile: %v", err)
+		return p
+	}
+	if err := pprof.StartCPUProfile(f); err != nil {
+		f.Close()
+		cfg.warnf("cpu profile: %v", err)
+		return p
+	}
+	p.cpu = f
+	return p
+}
+
+// Останавливает CPU профиль и записывает профиль кучи WithHeapProfile
+func (p *profiler) stop() {
+	if p.cpu != nil {
+		pprof.StopCPUProfile()
+		if err := p.cpu.Close(); err != nil {
+			p.cfg.warnf("cpu profile: %v", err)
+		}
+	}
+	if p.cfg.HeapProfile != "" {
+		if err := writeHeapProfile(p.cfg.HeapProfile); err != nil {
+			p.cfg.warnf("heap profile: %v", err)
+		}
+	}
+}
+
+func writeHeapProfile(path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := pprof.WriteHeapProfile(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
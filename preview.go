@@ -0,0 +1,64 @@
+package gohttptest
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"slices"
+	"strconv"
+)
+
+// Сколько байт тела показывать в предпросмотре
+const previewBodyBytes = 512
+
+// Печатает первый запрос в формате HTTP/1.1 так, как он уйдет на сервер.
+// Секретные заголовки скрыты
+func printPreview(ctx context.Context, w io.Writer, cfg *Config, transport *http.Transport, t target) error {
+	req, size, err := newWorker(0, cfg, transport).newRequest(ctx, t)
+	if err != nil {
+		return err
+	}
+	var body []byte
+	if req.Body != nil {
+		body, err = io.ReadAll(io.LimitReader(req.Body, previewBodyBytes))
+		req.Body.Close()
+		if err != nil {
+			return fmt.Errorf("read body: %w", err)
+		}
+	}
+
+	host := req.Host
+	if host == "" {
+		host = req.URL.Host
+	}
+	headers := redactHeaders(req.Header, cfg)
+	if size > 0 {
+		headers.Set("Content-Length", strconv.FormatInt(size, 10))
+	}
+
+	fmt.Fprintln(w, "Request preview:")
+	fmt.Fprintf(w, "%s %s HTTP/1.1\n", req.Method, req.URL.RequestURI())
+	fmt.Fprintf(w, "Host: %s\n", host)
+	for _, name := range slices.Sorted(maps.Keys(headers)) {
+		for _, v := range headers[name] {
+			fmt.Fprintf(w, "%s: %s\n", name, v)
+		}
+	}
+	fmt.Fprintln(w)
+	if len(body) > 0 {
+		if enc := req.Header.Get("Content-Encoding"); enc != "" {
+			// Сжатое тело не читается, показывается только размер
+			fmt.Fprintf(w, "[%d bytes, %s]\n", size, enc)
+		} else {
+			w.Write(body)
+			if size > int64(len(body)) {
+				fmt.Fprintf(w, "\n[%d more bytes]", size-int64(len(body)))
+			}
+			fmt.Fprintln(w)
+		}
+		fmt.Fprintln(w)
+	}
+	return nil
+}
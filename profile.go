@@ -0,0 +1,112 @@
+package gohttptest
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Как часто профиль нагрузки пересчитывает количество воркеров
+const profileInterval = 100 * time.Millisecond
+
+// Профиль нагрузки для WithLoadProfile: желаемое количество воркеров
+// через elapsed после начала теста. Значения меньше 1 считаются 1
+type LoadProfile interface {
+	Concurrency(elapsed time.Duration) int
+}
+
+// Ступенчатый рост: Start воркеров, затем +Step каждые Every, но не больше
+// Max (0 - без ограничения)
+type StepProfile struct {
+	Start int
+	Step  int
+	Every time.Duration
+	Max   int
+}
+
+func (p StepProfile) Concurrency(elapsed time.Duration) int {
+	n := p.Start
+	if p.Every > 0 {
+		n += p.Step * int(elapsed/p.Every)
+	}
+	if p.Max > 0 {
+		n = min(n, p.Max)
+	}
+	return n
+}
+
+func (p StepProfile) String() string {
+	return fmt.Sprintf("step, %d workers +%d every %v, max %d", p.Start, p.Step, p.Every, p.Max)
+}
+
+// Постоянная нагрузка Base с всплеском до Spike воркеров с момента At
+// на время Length
+type SpikeProfile struct {
+	Base   int
+	Spike  int
+	At     time.Duration
+	Length time.Duration
+}
+
+func (p SpikeProfile) Concurrency(elapsed time.Duration) int {
+	if elapsed >= p.At && elapsed < p.At+p.Length {
+		return p.Spike
+	}
+	return p.Base
+}
+
+func (p SpikeProfile) String() string {
+	return fmt.Sprintf("spike, %d workers, %d at %v for %v", p.Base, p.Spike, p.At, p.Length)
+}
+
+// Постоянная нагрузка Workers воркеров для долгих тестов на утечки и
+// деградацию, длительность задается WithDuration
+type SoakProfile struct {
+	Workers int
+}
+
+func (p SoakProfile) Concurrency(time.Duration) int {
+	return p.Workers
+}
+
+func (p SoakProfile) String() string {
+	return fmt.Sprintf("soak, %d workers", p.Workers)
+}
+
+// Запускает и останавливает воркеры по профилю, пока не отменен ctx или
+// не закончились задания (закрыт finished)
+func runProfile(ctx context.Context, p LoadProfile, finished <-chan struct{}, startWorker func(workerID int, quit <-chan struct{})) {
+	start := time.Now()
+	var (
+		quits  []chan struct{}
+		nextID int
+	)
+	adjust := func() {
+		want := max(p.Concurrency(time.Since(start)), 1)
+		for len(quits) < want {
+			quit := make(chan struct{})
+			startWorker(nextID, quit)
+			nextID++
+			quits = append(quits, quit)
+		}
+		// Останавливаются последние запущенные воркеры
+		for len(quits) > want {
+			close(quits[len(quits)-1])
+			quits = quits[:len(quits)-1]
+		}
+	}
+
+	adjust()
+	ticker := time.NewTicker(profileInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-finished:
+			return
+		case <-ticker.C:
+			adjust()
+		}
+	}
+}
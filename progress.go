@@ -0,0 +1,89 @@
+package gohttptest
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"golang.org/x/term"
+)
+
+// Живой прогресс теста: раз в секунду печатает строку вида
+// [15s] Req: 1200 | RPS: 80.0 | p99: 142ms | Err: 2 | Workers: 50
+type progress struct {
+	w     io.Writer
+	st    *stats
+	start time.Time
+	tty   bool
+	stop  chan struct{}
+	wg    sync.WaitGroup
+}
+
+func startProgress(w io.Writer, st *stats, start time.Time) *progress {
+	p := &progress{
+		w:     w,
+		st:    st,
+		start: start,
+		tty:   isTerminal(w),
+		stop:  make(chan struct{}),
+	}
+	p.wg.Add(1)
+	go p.run()
+	return p
+}
+
+func (p *progress) run() {
+	defer p.wg.Done()
+
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+
+	var (
+		prevRequests int64
+		printed      bool
+	)
+	for {
+		select {
+		case <-p.stop:
+			if printed {
+				fmt.Fprintln(p.w)
+			}
+			return
+		case <-ticker.C:
+			snap := p.st.live()
+
+			line := fmt.Sprintf("[%v] Req: %d | RPS: %.1f | p99: %v | Err: %d | Workers: %d",
+				time.Since(p.start).Round(time.Second),
+				snap.requests,
+				float64(snap.requests-prevRequests),
+				snap.p99.Round(time.Microsecond),
+				snap.failed,
+				snap.workers,
+			)
+			prevRequests = snap.requests
+
+			if p.tty {
+				fmt.Fprintf(p.w, "\r\033[K%s", line)
+			} else {
+				fmt.Fprintln(p.w, line)
+			}
+			printed = true
+		}
+	}
+}
+
+// Останавливает вывод прогресса и ждет завершения горутины
+func (p *progress) close() {
+	close(p.stop)
+	p.wg.Wait()
+}
+
+func isTerminal(w io.Writer) bool {
+	if s, ok := w.(*syncWriter); ok {
+		w = s.w
+	}
+	f, ok := w.(*os.File)
+	return ok && term.IsTerminal(int(f.Fd()))
+}
@@ -0,0 +1,43 @@
+package gohttptest
+
+import (
+	"net/http"
+	"strings"
+)
+
+// Заголовки, значения которых нельзя выводить целиком
+var secretHeaders = []string{"Authorization", "Proxy-Authorization"}
+
+// Копия заголовков со скрытыми секретами
+func redactHeaders(h http.Header, cfg *Config) http.Header {
+	out := h.Clone()
+	for _, name := range secretHeaders {
+		redactHeader(out, name)
+	}
+	if cfg.APIKeyHeader != "" {
+		redactHeader(out, cfg.APIKeyHeader)
+	}
+	return out
+}
+
+func redactHeader(h http.Header, name string) {
+	values := h.Values(name)
+	for i, v := range values {
+		values[i] = redact(v)
+	}
+}
+
+// Оставляет схему авторизации ("Bearer", "Basic") и первые 8 символов
+// самого секрета
+func redact(value string) string {
+	scheme, secret, ok := strings.Cut(value, " ")
+	if !ok {
+		scheme, secret = "", value
+	} else {
+		scheme += " "
+	}
+	if len(secret) <= 8 {
+		return scheme + "..."
+	}
+	return scheme + secret[:8] + "..."
+}
@@ -0,0 +1,233 @@
+package gohttptest
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Минимальное количество запросов, при котором p99.99 имеет смысл
+const minSamplesP9999 = 10000
+
+// Пишет итоговый отчет в формате format
+func writeReport(w io.Writer, format string, r BenchmarkResult) error {
+	if format == FormatJSON {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(r)
+	}
+	printReport(w, r)
+	return nil
+}
+
+// Печатает итоговый отчет в текстовом виде
+func printReport(w io.Writer, r BenchmarkResult) {
+	if r.Name != "" {
+		fmt.Fprintf(w, "%s: ", r.Name)
+	}
+	fmt.Fprintln(w, "BENCHMARK RESULTS")
+
+	fmt.Fprintf(w, "Time taken:           %v\n", r.TotalDuration.Round(time.Millisecond))
+	switch r.AbortReason {
+	case StopRequestCount, StopDuration:
+	case StopInterrupt:
+		fmt.Fprintf(w, "Test interrupted after %v, results are partial\n", r.TotalDuration.Round(time.Millisecond))
+	default:
+		fmt.Fprintf(w, "Stopped:              %s\n", r.AbortReason)
+	}
+	if r.WarmupDuration > 0 {
+		fmt.Fprintf(w, "Warm-up time:         %v\n", r.WarmupDuration.Round(time.Millisecond))
+	}
+	fmt.Fprintf(w, "Total requests:       %d\n", r.TotalRequests)
+	if r.ResumedRequests > 0 {
+		fmt.Fprintf(w, "  From checkpoint:    %d\n", r.ResumedRequests)
+	}
+	fmt.Fprintf(w, "Successful requests:  %d\n", r.SuccessCount)
+	fmt.Fprintf(w, "Failed requests:      %d\n", r.FailedCount)
+	if r.FailedCount > 0 {
+		fmt.Fprintf(w, "  Network errors:     %d\n", r.NetworkErrors)
+		fmt.Fprintf(w, "  Wrong status:       %d\n", r.StatusErrors)
+	}
+	if r.DialTimeouts > 0 || r.ResponseTimeouts > 0 {
+		fmt.Fprintf(w, "  Dial timeouts:      %d\n", r.DialTimeouts)
+		fmt.Fprintf(w, "  Response timeouts:  %d\n", r.ResponseTimeouts)
+	}
+	if r.ContentLengthMismatches > 0 {
+		fmt.Fprintf(w, "  Length mismatches:  %d\n", r.ContentLengthMismatches)
+	}
+	if r.BodyMismatches > 0 {
+		fmt.Fprintf(w, "  Body mismatches:    %d\n", r.BodyMismatches)
+	}
+	if r.TotalRetries > 0 {
+		fmt.Fprintf(w, "Retries:              %d\n", r.TotalRetries)
+	}
+	if r.MaxRedirects > 0 {
+		fmt.Fprintf(w, "Redirects:            avg %.2f, max %d\n", r.AvgRedirects, r.MaxRedirects)
+	}
+	fmt.Fprintf(w, "Requests per second:  %.2f\n", r.RequestsPerSecond)
+
+	if r.TotalRequests > 0 {
+		fmt.Fprintf(w, "Average duration:     %v\n", r.AvgDuration.Round(time.Microsecond))
+		fmt.Fprintf(w, "Std deviation:        %v (CV %.1f%%)\n", r.StdDev.Round(time.Microsecond), r.CoefficientOfVariation)
+		fmt.Fprintf(w, "Min duration:         %v\n", r.MinDuration.Round(time.Microsecond))
+		fmt.Fprintf(w, "Max duration:         %v\n", r.MaxDuration.Round(time.Microsecond))
+		fmt.Fprintf(w, "50th percentile:      %v\n", r.P50.Round(time.Microsecond))
+		fmt.Fprintf(w, "90th percentile:      %v\n", r.P90.Round(time.Microsecond))
+		fmt.Fprintf(w, "95th percentile:      %v\n", r.P95.Round(time.Microsecond))
+		fmt.Fprintf(w, "99th percentile:      %v\n", r.P99.Round(time.Microsecond))
+		fmt.Fprintf(w, "99.9th percentile:    %v\n", r.P999.Round(time.Microsecond))
+		fmt.Fprintf(w, "99.99th percentile:   %v\n", r.P9999.Round(time.Microsecond))
+		if r.CorrectedSamples > 0 {
+			fmt.Fprintf(w, "  (percentiles include %d samples added by coordinated omission correction)\n", r.CorrectedSamples)
+		}
+		if r.TotalRequests < minSamplesP9999 {
+			fmt.Fprintf(w, "  (note: %d samples is too few for a meaningful p99.99, need at least %d)\n",
+				r.TotalRequests, minSamplesP9999)
+		}
+
+		if r.TTFBP50 > 0 {
+			fmt.Fprintf(w, "TTFB 50th percentile: %v\n", r.TTFBP50.Round(time.Microsecond))
+			fmt.Fprintf(w, "TTFB 95th percentile: %v\n", r.TTFBP95.Round(time.Microsecond))
+			fmt.Fprintf(w, "TTFB 99th percentile: %v\n", r.TTFBP99.Round(time.Microsecond))
+		}
+
+		if r.TotalDuration > 0 {
+			fmt.Fprintf(w, "Throughput:           %.2f KB/s\n", r.ThroughputKBps)
+			if r.CompressedResponses > 0 {
+				fmt.Fprintf(w, "Compression:          %.2fx (%d gzip responses)\n", r.CompressionRatio, r.CompressedResponses)
+			}
+			if r.UploadThroughputKBps > 0 {
+				fmt.Fprintf(w, "Upload throughput:    %.2f KB/s\n", r.UploadThroughputKBps)
+			}
+		}
+
+		fmt.Fprintf(w, "Success rate:         %.1f%%\n", r.SuccessRate)
+	}
+
+	if r.DNSLookups > 0 {
+		fmt.Fprintf(w, "DNS lookups:          %d (avg %v, min %v, max %v)\n", r.DNSLookups,
+			r.DNSAvgDuration.Round(time.Microsecond),
+			r.DNSMinDuration.Round(time.Microsecond),
+			r.DNSMaxDuration.Round(time.Microsecond))
+	}
+	if r.TLSHandshakes > 0 {
+		fmt.Fprintf(w, "TLS handshakes:       %d (avg %v, min %v, max %v)\n", r.TLSHandshakes,
+			r.TLSHandshakeAvgDuration.Round(time.Microsecond),
+			r.TLSHandshakeMinDuration.Round(time.Microsecond),
+			r.TLSHandshakeMaxDuration.Round(time.Microsecond))
+	}
+	if r.TotalRequests > 0 && r.connTracked {
+		fmt.Fprintf(w, "New connections:      %d\n", r.NewConnections)
+		fmt.Fprintf(w, "Connection reuse:     %.1f%%\n", r.ConnectionReuseRate)
+	}
+	if len(r.Protocols) > 0 {
+		protos := slices.Sorted(maps.Keys(r.Protocols))
+		parts := make([]string, len(protos))
+		for i, p := range protos {
+			parts[i] = fmt.Sprintf("%s: %d", p, r.Protocols[p])
+		}
+		fmt.Fprintf(w, "Protocols:            %s\n", strings.Join(parts, " | "))
+	}
+	if r.ViaResponses > 0 {
+		fmt.Fprintf(w, "Responses via proxy:  %d\n", r.ViaResponses)
+	}
+
+	printStatusCodes(w, r.StatusCodeCounts)
+
+	if len(r.URLStats) > 1 {
+		width := 0
+		for _, u := range r.URLStats {
+			width = max(width, len(u.URL))
+		}
+		fmt.Fprintln(w, "Per URL:")
+		for _, u := range r.URLStats {
+			fmt.Fprintf(w, "  %-*s  total %d | ok %d | failed %d\n", width, u.URL, u.TotalRequests, u.SuccessCount, u.FailedCount)
+		}
+	}
+
+	if len(r.ResourceSnapshots) > 0 {
+		fmt.Fprintf(w, "Peak heap:            %.2f MB alloc, %.2f MB in use\n",
+			float64(r.PeakHeapAlloc)/(1<<20), float64(r.PeakHeapInuse)/(1<<20))
+		fmt.Fprintf(w, "Max goroutines:       %d\n", r.MaxGoroutines)
+	}
+
+	if len(r.Annotations) > 0 {
+		fmt.Fprintln(w, "Annotations:")
+		for _, a := range r.Annotations {
+			fmt.Fprintf(w, "  %10v  %s\n", a.At.Round(time.Millisecond), a.Label)
+		}
+	}
+
+	if len(r.HeaderFailures) > 0 {
+		fmt.Fprintln(w, "Header check failures:")
+		for _, h := range slices.Sorted(maps.Keys(r.HeaderFailures)) {
+			fmt.Fprintf(w, "  %s: %d\n", h, r.HeaderFailures[h])
+		}
+	}
+
+	if len(r.distribution) > 0 {
+		printDistribution(w, r.distribution)
+	}
+
+	if r.TotalRequests > 0 {
+		fmt.Fprintf(w, "Apdex (T=%v):%*s%.3f\n", r.ApdexThreshold, max(1, 11-len(r.ApdexThreshold.String())), "", r.ApdexScore)
+	}
+}
+
+// Печатает количество ответов по кодам статуса, отсортированное по коду
+func printStatusCodes(w io.Writer, counts map[int]int) {
+	codes := slices.Sorted(maps.Keys(counts))
+	codes = slices.DeleteFunc(codes, func(c int) bool { return counts[c] == 0 })
+	if len(codes) == 0 {
+		return
+	}
+
+	width := 0
+	for _, c := range codes {
+		width = max(width, len(strconv.Itoa(counts[c])))
+	}
+
+	fmt.Fprintln(w, "Status codes:")
+	for _, c := range codes {
+		fmt.Fprintf(w, "  %d: %*d\n", c, width, counts[c])
+	}
+}
+
+// Печатает ASCII гистограмму, ширина полосы зависит от ширины терминала
+func printDistribution(w io.Writer, buckets []distBucket) {
+	var total, peak int64
+	for _, b := range buckets {
+		total += b.count
+		peak = max(peak, b.count)
+	}
+	if total == 0 {
+		return
+	}
+
+	labels := make([]string, len(buckets))
+	labelWidth, countWidth := 0, len(strconv.FormatInt(peak, 10))
+	for i, b := range buckets {
+		labels[i] = fmt.Sprintf("%v - %v", b.from.Round(time.Microsecond), b.to.Round(time.Microsecond))
+		labelWidth = max(labelWidth, len(labels[i]))
+	}
+
+	columns := 80
+	if n, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil && n > 0 {
+		columns = n
+	}
+	// Отступ, подпись, " | ", пробел, количество и " (100.0%)"
+	barWidth := max(columns-2-labelWidth-3-1-countWidth-9, 10)
+
+	fmt.Fprintln(w, "Latency distribution:")
+	for i, b := range buckets {
+		bar := strings.Repeat("█", int(b.count*int64(barWidth)/peak))
+		fmt.Fprintf(w, "  %*s | %s %*d (%.1f%%)\n", labelWidth, labels[i], bar, countWidth, b.count,
+			float64(b.count)/float64(total)*100)
+	}
+}
@@ -0,0 +1,80 @@
+package gohttptest
+
+import (
+	"runtime"
+	"sync"
+	"time"
+)
+
+// Как часто WithResourceTracking записывает использование памяти
+const resourceInterval = 10 * time.Second
+
+// Память и горутины самого gohttptest в момент теста
+type ResourceSnapshot struct {
+	// Время от начала теста, включая прогрев
+	At time.Duration `json:"at"`
+	// runtime.MemStats, байт
+	HeapAlloc  uint64 `json:"heapAlloc"`
+	HeapInuse  uint64 `json:"heapInuse"`
+	Goroutines int    `json:"goroutines"`
+}
+
+func (s ResourceSnapshot) MarshalJSON() ([]byte, error) {
+	return marshalWithDurations(s)
+}
+
+// Снимки ресурсов в начале теста, каждые resourceInterval и в конце
+type resourceTracker struct {
+	start     time.Time
+	snapshots []ResourceSnapshot
+	stop      chan struct{}
+	wg        sync.WaitGroup
+}
+
+func newResourceTracker(start time.Time) *resourceTracker {
+	t := &resourceTracker{start: start, stop: make(chan struct{})}
+	t.record()
+	t.wg.Add(1)
+	go t.run()
+	return t
+}
+
+func (t *resourceTracker) run() {
+	defer t.wg.Done()
+
+	ticker := time.NewTicker(resourceInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-t.stop:
+			return
+		case <-ticker.C:
+			t.record()
+		}
+	}
+}
+
+func (t *resourceTracker) record() {
+	var m runtime.MemStats
+	runtime.ReadMemStats(&m)
+	t.snapshots = append(t.snapshots, ResourceSnapshot{
+		At:         time.Since(t.start),
+		HeapAlloc:  m.HeapAlloc,
+		HeapInuse:  m.HeapInuse,
+		Goroutines: runtime.NumGoroutine(),
+	})
+}
+
+// Останавливает запись, делает последний снимок и добавляет снимки и
+// пиковые значения в r
+func (t *resourceTracker) close(r *BenchmarkResult) {
+	close(t.stop)
+	t.wg.Wait()
+	t.record()
+	r.ResourceSnapshots = t.snapshots
+	for _, s := range t.snapshots {
+		r.PeakHeapAlloc = max(r.PeakHeapAlloc, s.HeapAlloc)
+		r.PeakHeapInuse = max(r.PeakHeapInuse, s.HeapInuse)
+		r.MaxGoroutines = max(r.MaxGoroutines, s.Goroutines)
+	}
+}
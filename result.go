@@ -0,0 +1,603 @@
+package gohttptest
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"math"
+	"net/http"
+	"slices"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Результат одного запроса
+type result struct {
+	// Время начала запроса
+	Start      time.Time
+	StatusCode int
+	Duration   time.Duration
+	Bytes      int64
+	// Размер тела запроса
+	RequestBytes int64
+	Error        error
+	// Протокол ответа (resp.Proto)
+	Proto string
+	// Ответ содержит заголовок Via (прошел через прокси)
+	Via bool
+	// Время до первого байта ответа
+	TTFB time.Duration
+	// Время DNS запроса, 0 если соединение переиспользовано
+	DNSDuration time.Duration
+	// Время TLS рукопожатия, 0 для http:// и переиспользованных соединений
+	TLSHandshake time.Duration
+	// Вид таймаута, если запрос закончился таймаутом
+	Timeout timeoutKind
+	// Причина неуспеха по содержимому ответа
+	FailureReason failureReason
+	// Заголовки, не прошедшие проверку WithRequiredHeader
+	FailedHeaders []string
+	// Номер воркера, выполнившего запрос
+	Worker int
+	// Индекс адреса в WithURLs, 0 для единственного адреса
+	URLIndex int
+	// Секунда теста, в которую завершился запрос (от начала теста)
+	SecondBucket time.Duration
+	// Количество повторов до итоговой попытки
+	Retries int
+	// Значение заголовка WithRequestIDHeader
+	RequestID string
+	// Количество выполненных редиректов
+	Redirects int
+	// Размер тела gzip ответа до и после распаковки, 0 для несжатых ответов
+	CompressedBytes   int64
+	DecompressedBytes int64
+	// Запрос фазы прогрева
+	Warmup bool
+	// Отправленные заголовки, заполняется только в режиме Verbose
+	Headers http.Header
+}
+
+// Причина, по которой ответ признан неуспешным при проверке содержимого
+type failureReason int
+
+const (
+	noFailure failureReason = iota
+	headerFailure
+	bodyFailure
+)
+
+// Итоговая статистика теста.
+// В JSON длительности записываются в наносекундах, рядом с каждой
+// добавляется поле <name>Human с читаемым значением
+type BenchmarkResult struct {
+	// Имя сценария из WithName
+	Name string `json:"name"`
+
+	TotalRequests int `json:"totalRequests"`
+	SuccessCount  int `json:"successCount"`
+	FailedCount   int `json:"failedCount"`
+
+	// Почему тест остановился: одна из констант Stop*
+	AbortReason string `json:"abortReason"`
+	// Запросы из контрольной точки WithCheckpointFile, выполненные
+	// прерванными запусками. Остальная статистика - только этого запуска
+	ResumedRequests int `json:"resumedRequests,omitempty"`
+
+	// Общее время измерения (wall-clock), без прогрева
+	TotalDuration time.Duration `json:"totalDuration"`
+	// Время прогрева
+	WarmupDuration time.Duration `json:"warmupDuration,omitempty"`
+	MinDuration    time.Duration `json:"minDuration"`
+	MaxDuration    time.Duration `json:"maxDuration"`
+	AvgDuration    time.Duration `json:"avgDuration"`
+	// Стандартное отклонение длительности (по генеральной совокупности)
+	StdDev time.Duration `json:"stdDev"`
+	// Коэффициент вариации StdDev/AvgDuration в процентах
+	CoefficientOfVariation float64 `json:"coefficientOfVariation"`
+
+	P50 time.Duration `json:"p50"`
+	P90 time.Duration `json:"p90"`
+	P95 time.Duration `json:"p95"`
+	P99 time.Duration `json:"p99"`
+	// 99.9 и 99.99 перцентили, имеют смысл при большом количестве запросов
+	P999  time.Duration `json:"p999"`
+	P9999 time.Duration `json:"p9999"`
+	// Перцентили времени до первого байта ответа (TTFB)
+	TTFBP50 time.Duration `json:"ttfbP50"`
+	TTFBP95 time.Duration `json:"ttfbP95"`
+	TTFBP99 time.Duration `json:"ttfbP99"`
+	// Количество значений, добавленных поправкой на coordinated omission.
+	// Перцентили и StdDev учитывают эти значения
+	CorrectedSamples int64 `json:"correctedSamples,omitempty"`
+
+	// Ответы gzip и степень сжатия: суммарный размер после распаковки,
+	// деленный на суммарный размер до распаковки
+	CompressedResponses int     `json:"compressedResponses"`
+	CompressionRatio    float64 `json:"compressionRatio"`
+
+	RequestsPerSecond float64 `json:"requestsPerSecond"`
+	ThroughputKBps    float64 `json:"throughputKBps"`
+	// Скорость отправки тел запросов
+	UploadThroughputKBps float64 `json:"uploadThroughputKBps"`
+	// Доля успешных запросов в процентах
+	SuccessRate float64 `json:"successRate"`
+
+	// Разбивка неуспешных запросов: сетевые ошибки (нет ответа) и
+	// ответы с неожидаемым кодом статуса
+	NetworkErrors int `json:"networkErrors"`
+	StatusErrors  int `json:"statusErrors"`
+
+	// Общее количество повторов запросов
+	TotalRetries int `json:"totalRetries"`
+
+	// Среднее и максимальное количество редиректов на запрос
+	AvgRedirects float64 `json:"avgRedirects"`
+	MaxRedirects int     `json:"maxRedirects"`
+
+	// Ошибки по таймауту соединения и таймауту ответа
+	DialTimeouts     int `json:"dialTimeouts"`
+	ResponseTimeouts int `json:"responseTimeouts"`
+
+	// Ответы, размер тела которых не совпал с Content-Length
+	ContentLengthMismatches int `json:"contentLengthMismatches"`
+
+	// Количество ответов, не прошедших проверку заголовка, по имени заголовка
+	HeaderFailures map[string]int `json:"headerFailures"`
+
+	// Количество ответов, тело которых не прошло проверку WithBodyRegex
+	BodyMismatches int `json:"bodyMismatches"`
+
+	// Количество ответов с заголовком Via, подтверждает работу через прокси
+	ViaResponses int `json:"viaResponses"`
+
+	// Время DNS запросов, только по запросам, которым он понадобился
+	DNSLookups     int           `json:"dnsLookups"`
+	DNSMinDuration time.Duration `json:"dnsMinDuration"`
+	DNSMaxDuration time.Duration `json:"dnsMaxDuration"`
+	DNSAvgDuration time.Duration `json:"dnsAvgDuration"`
+
+	// Время TLS рукопожатий, только по запросам с новым TLS соединением.
+	// Для HTTP/2 включает согласование протокола через ALPN
+	TLSHandshakes           int           `json:"tlsHandshakes"`
+	TLSHandshakeMinDuration time.Duration `json:"tlsHandshakeMinDuration"`
+	TLSHandshakeMaxDuration time.Duration `json:"tlsHandshakeMaxDuration"`
+	TLSHandshakeAvgDuration time.Duration `json:"tlsHandshakeAvgDuration"`
+
+	// Количество новых TCP соединений за время измерения
+	NewConnections int64 `json:"newConnections"`
+	// Доля запросов, выполненных на уже открытом соединении, в процентах
+	ConnectionReuseRate float64 `json:"connectionReuseRate"`
+	// Соединения считались (не считаются при WithClient)
+	connTracked bool
+	// Распределение длительностей для WithHistogram
+	distribution []distBucket
+
+	// Количество ответов по протоколу (HTTP/1.1, HTTP/2.0)
+	Protocols map[string]int `json:"protocols"`
+
+	// Количество ответов по каждому коду статуса
+	StatusCodeCounts map[int]int `json:"statusCodeCounts"`
+
+	// Индекс Apdex от 0 до 1: (довольные + терпящие/2) / все запросы.
+	// Неуспешные запросы считаются недовольными
+	ApdexScore     float64       `json:"apdexScore"`
+	ApdexThreshold time.Duration `json:"apdexThreshold"`
+
+	// Статистика по секундам теста
+	TimeSeries []TimePoint `json:"timeSeries"`
+	// Отметки TestRun.Annotate в порядке добавления
+	Annotations []Annotation `json:"annotations,omitempty"`
+
+	// Статистика по каждому адресу, только для WithURLs
+	URLStats []URLStats `json:"urlStats,omitempty"`
+
+	// Память и горутины gohttptest, только для WithResourceTracking
+	ResourceSnapshots []ResourceSnapshot `json:"resourceSnapshots,omitempty"`
+	PeakHeapAlloc     uint64             `json:"peakHeapAlloc,omitempty"`
+	PeakHeapInuse     uint64             `json:"peakHeapInuse,omitempty"`
+	MaxGoroutines     int                `json:"maxGoroutines,omitempty"`
+}
+
+// Количество запросов к одному адресу
+type URLStats struct {
+	URL           string `json:"url"`
+	TotalRequests int    `json:"totalRequests"`
+	SuccessCount  int    `json:"successCount"`
+	FailedCount   int    `json:"failedCount"`
+}
+
+// Статистика за одну секунду теста
+type TimePoint struct {
+	// Номер секунды от начала теста, включая прогрев
+	Second       int           `json:"second"`
+	RequestCount int           `json:"requestCount"`
+	ErrorCount   int           `json:"errorCount"`
+	AvgDuration  time.Duration `json:"avgDuration"`
+	// Отметки, пришедшиеся на эту секунду
+	Annotations []string `json:"annotations,omitempty"`
+}
+
+func (p TimePoint) MarshalJSON() ([]byte, error) {
+	return marshalWithDurations(p)
+}
+
+// Корзина гистограммы распределения: значения в [from, to)
+type distBucket struct {
+	from, to time.Duration
+	count    int64
+}
+
+func (r BenchmarkResult) MarshalJSON() ([]byte, error) {
+	return marshalWithDurations(r)
+}
+
+func (r *BenchmarkResult) setConnections(n int64) {
+	r.NewConnections = n
+	r.connTracked = true
+	if r.TotalRequests > 0 {
+		r.ConnectionReuseRate = max(0, float64(int64(r.TotalRequests)-n)/float64(r.TotalRequests)*100)
+	}
+}
+
+// Накопитель результатов запросов
+type stats struct {
+	totalRequests int
+	successCount  int
+	failedCount   int
+	totalDuration time.Duration
+	totalBytes    int64
+	totalSent     int64
+	networkErrors int
+	retries       int
+	redirects     int
+	compressed    int
+	zipBytes      int64
+	unzipBytes    int64
+	maxRedirects  int
+	statusErrors  int
+	// Ожидаемые коды статуса, nil - успешен любой код < 400
+	expected     map[int]bool
+	statusCodes  map[int]int
+	timeouts     map[timeoutKind]int
+	viaResponses int
+	lengthErrors int
+	bodyErrors   int
+	headerErrors map[string]int
+	protocols    map[string]int
+	dns          phaseStats
+	tls          phaseStats
+	// Ожидаемый интервал между запросами одного воркера для поправки
+	// на coordinated omission, 0 - без поправки
+	coInterval time.Duration
+	corrected  int64
+	// Количество корзин распределения в отчете, 0 - не считать
+	distBuckets int
+	// Apdex: порог и количество довольных (< T) и терпящих (< 4T)
+	apdexT     time.Duration
+	satisfied  int
+	tolerating int
+	// Статистика по секундам, индекс - номер секунды
+	series []secondStats
+	// Статистика по адресам WithURLs, индекс - URLIndex
+	perURL []URLStats
+
+	// Счетчики живой статистики, читаются из горутины прогресса
+	liveRequests atomic.Int64
+	liveFailed   atomic.Int64
+	liveWorkers  atomic.Int64
+	// Ответы по классу статуса (1xx..5xx), 0 - без ответа
+	liveStatus [6]atomic.Int64
+	// Защищает hist
+	mu   sync.Mutex
+	hist *histogram
+	ttfb *histogram
+}
+
+func newStats(cfg *Config) *stats {
+	s := &stats{
+		statusCodes:  make(map[int]int),
+		timeouts:     make(map[timeoutKind]int),
+		protocols:    make(map[string]int),
+		headerErrors: make(map[string]int),
+		hist:         newHistogram(),
+		ttfb:         newHistogram(),
+	}
+	s.apdexT = cfg.ApdexThreshold
+	if cfg.Histogram {
+		s.distBuckets = 20
+	}
+	for _, u := range cfg.URLs {
+		s.perURL = append(s.perURL, URLStats{URL: displayURL(u)})
+	}
+	if len(cfg.ExpectedStatus) > 0 {
+		s.expected = make(map[int]bool, len(cfg.ExpectedStatus))
+		for _, code := range cfg.ExpectedStatus {
+			s.expected[code] = true
+		}
+	}
+	if cfg.CorrectCoordinatedOmission && cfg.RateLimit > 0 {
+		s.coInterval = time.Duration(float64(cfg.Concurrency) / cfg.RateLimit * float64(time.Second))
+	}
+	return s
+}
+
+func (s *stats) add(res result) {
+	s.totalRequests++
+	s.totalDuration += res.Duration
+	s.totalBytes += res.Bytes
+	s.totalSent += res.RequestBytes
+	s.retries += res.Retries
+	s.redirects += res.Redirects
+	s.maxRedirects = max(s.maxRedirects, res.Redirects)
+	if res.CompressedBytes > 0 {
+		s.compressed++
+		s.zipBytes += res.CompressedBytes
+		s.unzipBytes += res.DecompressedBytes
+	}
+
+	s.mu.Lock()
+	s.corrected += s.hist.recordCorrected(res.Duration, s.coInterval)
+	s.mu.Unlock()
+
+	if res.StatusCode != 0 {
+		s.statusCodes[res.StatusCode]++
+	}
+	if res.TTFB > 0 {
+		s.ttfb.record(res.TTFB)
+	}
+	s.dns.add(res.DNSDuration)
+	s.tls.add(res.TLSHandshake)
+	if res.Proto != "" {
+		s.protocols[res.Proto]++
+	}
+	if res.Via {
+		s.viaResponses++
+	}
+	if res.Timeout != noTimeout {
+		s.timeouts[res.Timeout]++
+	}
+	if errors.Is(res.Error, ErrContentLengthMismatch) {
+		s.lengthErrors++
+	}
+	if res.FailureReason == bodyFailure {
+		s.bodyErrors++
+	}
+	for _, h := range res.FailedHeaders {
+		s.headerErrors[h]++
+	}
+
+	s.liveRequests.Add(1)
+	if class := res.StatusCode / 100; class < len(s.liveStatus) {
+		s.liveStatus[class].Add(1)
+	}
+	switch {
+	case res.StatusCode == 0:
+		s.networkErrors++
+	case !s.statusOK(res.StatusCode):
+		s.statusErrors++
+	}
+	ok := s.succeeded(res)
+	if ok {
+		s.successCount++
+		switch {
+		case res.Duration < s.apdexT:
+			s.satisfied++
+		case res.Duration < 4*s.apdexT:
+			s.tolerating++
+		}
+	} else {
+		s.failedCount++
+		s.liveFailed.Add(1)
+	}
+
+	if res.URLIndex < len(s.perURL) {
+		u := &s.perURL[res.URLIndex]
+		u.TotalRequests++
+		if ok {
+			u.SuccessCount++
+		} else {
+			u.FailedCount++
+		}
+	}
+
+	sec := int(res.SecondBucket / time.Second)
+	for len(s.series) <= sec {
+		s.series = append(s.series, secondStats{})
+	}
+	s.series[sec].requests++
+	s.series[sec].total += res.Duration
+	if !ok {
+		s.series[sec].errors++
+	}
+}
+
+// Запрос успешен: без ошибки, прошел проверки содержимого и с ожидаемым кодом
+func (s *stats) succeeded(res result) bool {
+	return res.Error == nil && res.FailureReason == noFailure && s.statusOK(res.StatusCode)
+}
+
+// Код статуса считается успешным. 0 (нет ответа) всегда неуспешен
+func (s *stats) statusOK(code int) bool {
+	if code == 0 {
+		return false
+	}
+	if s.expected != nil {
+		return s.expected[code]
+	}
+	return code < 400
+}
+
+// Накопитель статистики одной секунды
+type secondStats struct {
+	requests int
+	errors   int
+	total    time.Duration
+}
+
+// Снимок живой статистики
+type liveSnapshot struct {
+	requests int64
+	failed   int64
+	workers  int64
+	p99      time.Duration
+}
+
+func (s *stats) live() liveSnapshot {
+	s.mu.Lock()
+	p99 := s.hist.percentile(0.99)
+	s.mu.Unlock()
+	return liveSnapshot{
+		requests: s.liveRequests.Load(),
+		failed:   s.liveFailed.Load(),
+		workers:  s.liveWorkers.Load(),
+		p99:      p99,
+	}
+}
+
+// Перцентиль длительности, p от 0 до 1
+func (s *stats) percentile(p float64) time.Duration {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.hist.percentile(p)
+}
+
+// Проверяет требования SLA, ошибка перечисляет все нарушения
+func (s *stats) checkSLAs(slas []SLA) error {
+	if len(slas) == 0 {
+		return nil
+	}
+	if s.totalRequests == 0 {
+		return errors.New("sla: no requests completed")
+	}
+	var errs []error
+	for _, sla := range slas {
+		got := s.percentile(sla.Percentile)
+		if got > sla.MaxDuration {
+			name := "p" + strconv.FormatFloat(math.Round(sla.Percentile*1e6)/1e4, 'f', -1, 64)
+			errs = append(errs, fmt.Errorf("%s = %v exceeds SLA of %v by %v", name,
+				got.Round(time.Microsecond), sla.MaxDuration, (got-sla.MaxDuration).Round(time.Microsecond)))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Считает итоговую статистику, totalTestTime-общее время теста
+func (s *stats) finish(totalTestTime time.Duration) BenchmarkResult {
+	r := BenchmarkResult{
+		TotalRequests: s.totalRequests,
+		SuccessCount:  s.successCount,
+		FailedCount:   s.failedCount,
+		NetworkErrors: s.networkErrors,
+		StatusErrors:  s.statusErrors,
+		TotalRetries:  s.retries,
+		MaxRedirects:  s.maxRedirects,
+
+		CompressedResponses: s.compressed,
+		TotalDuration:       totalTestTime,
+
+		DialTimeouts:     s.timeouts[dialTimeout],
+		ResponseTimeouts: s.timeouts[responseTimeout],
+		ViaResponses:     s.viaResponses,
+
+		ContentLengthMismatches: s.lengthErrors,
+		HeaderFailures:          maps.Clone(s.headerErrors),
+		BodyMismatches:          s.bodyErrors,
+		Protocols:               maps.Clone(s.protocols),
+
+		DNSLookups:     s.dns.count,
+		DNSMinDuration: s.dns.min,
+		DNSMaxDuration: s.dns.max,
+		DNSAvgDuration: s.dns.avg(),
+
+		TLSHandshakes:           s.tls.count,
+		TLSHandshakeMinDuration: s.tls.min,
+		TLSHandshakeMaxDuration: s.tls.max,
+		TLSHandshakeAvgDuration: s.tls.avg(),
+
+		StatusCodeCounts: maps.Clone(s.statusCodes),
+		ApdexThreshold:   s.apdexT,
+		URLStats:         slices.Clone(s.perURL),
+	}
+
+	for sec, p := range s.series {
+		if p.requests == 0 {
+			continue
+		}
+		r.TimeSeries = append(r.TimeSeries, TimePoint{
+			Second:       sec,
+			RequestCount: p.requests,
+			ErrorCount:   p.errors,
+			AvgDuration:  p.total / time.Duration(p.requests),
+		})
+	}
+
+	if s.zipBytes > 0 {
+		r.CompressionRatio = float64(s.unzipBytes) / float64(s.zipBytes)
+	}
+
+	if totalTestTime > 0 {
+		r.RequestsPerSecond = float64(s.totalRequests) / totalTestTime.Seconds()
+		r.ThroughputKBps = float64(s.totalBytes) / 1024 / totalTestTime.Seconds()
+		r.UploadThroughputKBps = float64(s.totalSent) / 1024 / totalTestTime.Seconds()
+	}
+
+	if s.totalRequests == 0 {
+		return r
+	}
+
+	r.AvgDuration = s.totalDuration / time.Duration(s.totalRequests)
+	r.AvgRedirects = float64(s.redirects) / float64(s.totalRequests)
+	r.ApdexScore = (float64(s.satisfied) + float64(s.tolerating)/2) / float64(s.totalRequests)
+	r.SuccessRate = float64(s.successCount) / float64(s.totalRequests) * 100
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	r.MinDuration = s.hist.minValue()
+	r.MaxDuration = s.hist.maxValue()
+
+	mean, stdDev := s.hist.meanValue(), s.hist.stdDev()
+	r.StdDev = time.Duration(stdDev)
+	if mean > 0 {
+		r.CoefficientOfVariation = stdDev / mean * 100
+	}
+
+	r.P50 = s.hist.percentile(0.50)
+	r.P90 = s.hist.percentile(0.90)
+	r.P95 = s.hist.percentile(0.95)
+	r.P99 = s.hist.percentile(0.99)
+	r.P999 = s.hist.percentile(0.999)
+	r.P9999 = s.hist.percentile(0.9999)
+	r.CorrectedSamples = s.corrected
+
+	if s.distBuckets > 0 {
+		r.distribution = distribution(s.hist, s.distBuckets)
+	}
+
+	r.TTFBP50 = s.ttfb.percentile(0.50)
+	r.TTFBP95 = s.ttfb.percentile(0.95)
+	r.TTFBP99 = s.ttfb.percentile(0.99)
+
+	return r
+}
+
+// Делит диапазон [min, max] гистограммы на n равных корзин
+func distribution(h *histogram, n int) []distBucket {
+	lo, hi := h.minValue(), h.maxValue()
+	width := max((hi-lo)/time.Duration(n), 1)
+	buckets := make([]distBucket, n)
+	for i := range buckets {
+		buckets[i].from = lo + time.Duration(i)*width
+		buckets[i].to = buckets[i].from + width
+	}
+	buckets[n-1].to = hi
+
+	h.forEach(func(value time.Duration, count int64) {
+		i := min(max(int((value-lo)/width), 0), n-1)
+		buckets[i].count += count
+	})
+	return buckets
+}
@@ -0,0 +1,129 @@
+package gohttptest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+)
+
+// Верхняя граница конкурентности в FindSaturationPoint и RunBreakpoint
+const maxSearchConcurrency = 4096
+
+// Длительность одного шага поиска, если не задан WithDuration или
+// WithRequestCount
+const defaultSaturationStep = 10 * time.Second
+
+// Результат FindSaturationPoint
+type SaturationResult struct {
+	// Наибольшая конкурентность, при которой выполнены ограничения
+	Concurrency int `json:"concurrency"`
+	// RPS и p99 на этой конкурентности
+	RequestsPerSecond float64       `json:"requestsPerSecond"`
+	P99               time.Duration `json:"p99"`
+	// Результаты всех шагов в порядке выполнения
+	Steps []BenchmarkResult `json:"steps"`
+}
+
+func (s SaturationResult) MarshalJSON() ([]byte, error) {
+	return marshalWithDurations(s)
+}
+
+// Ищет наибольшую конкурентность, при которой доля успешных запросов не
+// ниже порога и p99 не выше границы (WithSaturationThresholds). Конкурентность
+// удваивается от 1 до первого нарушения, затем граница уточняется двоичным
+// поиском с точностью 10%. Каждый шаг - отдельный тест длительностью
+// WithDuration или WithRequestCount, по умолчанию 10 секунд
+func FindSaturationPoint(ctx context.Context, site string, opts ...Option) (SaturationResult, error) {
+	base, err := NewConfig(opts...)
+	if err != nil {
+		return SaturationResult{}, err
+	}
+	if base.Duration <= 0 && base.Requests <= 0 {
+		base.Duration = defaultSaturationStep
+	}
+	if base.Context == nil {
+		base.Context = ctx
+	}
+	out := base.Output
+
+	var sat SaturationResult
+	passed := func(r BenchmarkResult) bool {
+		return r.TotalRequests > 0 &&
+			r.SuccessRate >= base.SaturationMinSuccessRate*100 &&
+			(base.SaturationMaxP99 <= 0 || r.P99 <= base.SaturationMaxP99)
+	}
+	// Выполняет шаг, false - ограничения нарушены
+	step := func(concurrency int) (bool, error) {
+		cfg := *base
+		cfg.Concurrency = concurrency
+		cfg.Name = fmt.Sprintf("concurrency %d", concurrency)
+		// Шаги печатаются одной строкой, полный отчет каждого не нужен
+		cfg.Output = io.Discard
+
+		r, err := run(cfg, site)
+		if r.TotalRequests == 0 && err != nil {
+			return false, err
+		}
+		if r.AbortReason == StopInterrupt || r.AbortReason == StopContext {
+			return false, errors.New("saturation search interrupted")
+		}
+		sat.Steps = append(sat.Steps, r)
+		// Ошибка при наличии результатов - нарушение WithSLAAssertion
+		ok := err == nil && passed(r)
+		if !base.Quiet {
+			printStep(out, concurrency, r, ok)
+		}
+		if ok {
+			sat.Concurrency = concurrency
+			sat.RequestsPerSecond = r.RequestsPerSecond
+			sat.P99 = r.P99
+		}
+		return ok, nil
+	}
+
+	good, bad := 0, 0
+	for c := 1; c <= maxSearchConcurrency; c *= 2 {
+		ok, err := step(c)
+		if err != nil {
+			return sat, err
+		}
+		if !ok {
+			bad = c
+			break
+		}
+		good = c
+	}
+	if good == 0 {
+		return sat, errors.New("saturation: constraints are not met even at concurrency 1")
+	}
+	for bad > 0 && bad-good > max(1, good/10) {
+		mid := (good + bad) / 2
+		ok, err := step(mid)
+		if err != nil {
+			return sat, err
+		}
+		if ok {
+			good = mid
+		} else {
+			bad = mid
+		}
+	}
+
+	if !base.Quiet {
+		fmt.Fprintf(out, "Saturation point: concurrency %d, %.2f req/s, p99 %v\n",
+			sat.Concurrency, sat.RequestsPerSecond, sat.P99.Round(time.Microsecond))
+	}
+	return sat, nil
+}
+
+// Печатает строку о шаге поиска, ok - ограничения выполнены
+func printStep(w io.Writer, concurrency int, r BenchmarkResult, ok bool) {
+	verdict := "ok"
+	if !ok {
+		verdict = "limit exceeded"
+	}
+	fmt.Fprintf(w, "Concurrency %4d: %10.2f req/s, p99 %v, success %.1f%% - %s\n",
+		concurrency, r.RequestsPerSecond, r.P99.Round(time.Microsecond), r.SuccessRate, verdict)
+}
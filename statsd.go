@@ -0,0 +1,78 @@
+package gohttptest
+
+import (
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Отправка метрик StatsD по UDP. Ошибки записи игнорируются:
+// метрики не должны влиять на сам тест
+type statsdClient struct {
+	conn   net.Conn
+	prefix string
+	st     *stats
+	stop   chan struct{}
+	wg     sync.WaitGroup
+}
+
+func newStatsD(addr, prefix string, st *stats) (*statsdClient, error) {
+	conn, err := net.Dial("udp", addr)
+	if err != nil {
+		return nil, err
+	}
+	if prefix != "" && !strings.HasSuffix(prefix, ".") {
+		prefix += "."
+	}
+	c := &statsdClient{
+		conn:   conn,
+		prefix: prefix,
+		st:     st,
+		stop:   make(chan struct{}),
+	}
+	c.wg.Add(1)
+	go c.run()
+	return c, nil
+}
+
+// Раз в секунду отправляет RPS за прошедшую секунду
+func (c *statsdClient) run() {
+	defer c.wg.Done()
+
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+
+	var prev int64
+	for {
+		select {
+		case <-c.stop:
+			return
+		case <-ticker.C:
+			n := c.st.liveRequests.Load()
+			c.send(fmt.Sprintf("%srps:%d|g", c.prefix, n-prev))
+			prev = n
+		}
+	}
+}
+
+// Метрики одного завершенного запроса, одним пакетом
+func (c *statsdClient) record(res result, ok bool) {
+	outcome := "failed"
+	if ok {
+		outcome = "success"
+	}
+	c.send(fmt.Sprintf("%[1]srequests.total:1|c\n%[1]srequests.%[2]s:1|c\n%[1]sresponse_time:%.3[3]f|ms",
+		c.prefix, outcome, float64(res.Duration)/float64(time.Millisecond)))
+}
+
+func (c *statsdClient) send(packet string) {
+	c.conn.Write([]byte(packet))
+}
+
+func (c *statsdClient) close() {
+	close(c.stop)
+	c.wg.Wait()
+	c.conn.Close()
+}
@@ -0,0 +1,95 @@
+package gohttptest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+)
+
+// Сценарий набора тестов для RunSuite
+type ScenarioConfig struct {
+	// Имя сценария в отчетах, заменяет Config.Name. Без имени -
+	// "scenario N"
+	Name string
+	// Адрес, если в Config не заданы URLs или WeightedURLs
+	URL string
+	// Настройки из NewConfig или LoadConfig
+	Config *Config
+	// Пауза перед следующим сценарием
+	PauseBetween time.Duration
+}
+
+// Выполняет сценарии по очереди и печатает сводную таблицу в Output первого
+// сценария. Ошибка сценария не останавливает набор, ошибки всех сценариев
+// объединяются. При отмене ctx возвращаются результаты выполненных сценариев
+func RunSuite(ctx context.Context, scenarios []ScenarioConfig) ([]BenchmarkResult, error) {
+	var (
+		results []BenchmarkResult
+		errs    []error
+	)
+	for i, sc := range scenarios {
+		if sc.Config == nil {
+			errs = append(errs, fmt.Errorf("scenario %q: config is nil", sc.Name))
+			continue
+		}
+		cfg := *sc.Config
+		if sc.Name != "" {
+			cfg.Name = sc.Name
+		}
+		if cfg.Name == "" {
+			cfg.Name = fmt.Sprintf("scenario %d", i+1)
+		}
+		if cfg.Context == nil {
+			cfg.Context = ctx
+		}
+
+		bench, err := run(cfg, sc.URL)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("scenario %q: %w", cfg.Name, err))
+		}
+		if bench.TotalRequests > 0 || err == nil {
+			results = append(results, bench)
+		}
+		if ctx.Err() != nil {
+			errs = append(errs, ctx.Err())
+			break
+		}
+
+		if sc.PauseBetween > 0 && i < len(scenarios)-1 {
+			select {
+			case <-time.After(sc.PauseBetween):
+			case <-ctx.Done():
+				errs = append(errs, ctx.Err())
+			}
+			if ctx.Err() != nil {
+				break
+			}
+		}
+	}
+
+	if len(results) > 0 {
+		w := io.Writer(os.Stdout)
+		if scenarios[0].Config != nil && scenarios[0].Config.Output != nil {
+			w = scenarios[0].Config.Output
+		}
+		printSuiteSummary(w, results)
+	}
+	return results, errors.Join(errs...)
+}
+
+// Печатает таблицу сравнения сценариев
+func printSuiteSummary(w io.Writer, results []BenchmarkResult) {
+	width := len("Scenario")
+	for _, r := range results {
+		width = max(width, len(r.Name))
+	}
+	fmt.Fprintln(w, "SUITE SUMMARY")
+	fmt.Fprintf(w, "%-*s  %12s  %12s  %8s\n", width, "Scenario", "RPS", "p99", "Success")
+	for _, r := range results {
+		fmt.Fprintf(w, "%-*s  %12.2f  %12v  %7.1f%%\n", width, r.Name, r.RequestsPerSecond,
+			r.P99.Round(time.Microsecond), r.SuccessRate)
+	}
+}
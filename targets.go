@@ -0,0 +1,154 @@
+package gohttptest
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+	"sync/atomic"
+)
+
+// Адрес с весом для WithWeightedURLs. Пустой Method - метод теста,
+// nil Body - тело теста (WithBody, WithBodyFile). Headers добавляются
+// к заголовкам теста. URL, начинающийся с /, - путь относительно site
+type WeightedURL struct {
+	URL     string
+	Weight  int
+	Method  string
+	Body    []byte
+	Headers map[string]string
+}
+
+// Цель одного запроса
+type target struct {
+	url    string
+	method string
+	// nil - тело из настроек теста
+	body []byte
+	// Дополнительные заголовки цели
+	headers map[string]string
+	// 0 - адреса обходятся по кругу
+	weight int
+}
+
+// Собирает цели теста из site, WithURLs, WithWeightedURLs или WithURLTemplate.
+// cfg.URLs заполняется адресами целей для статистики по адресам
+func buildTargets(cfg *Config, site, method string) ([]target, error) {
+	if cfg.urlTemplate != nil {
+		u := cfg.URLTemplate
+		if strings.HasPrefix(u, "/") {
+			if site == "" {
+				return nil, fmt.Errorf("url template %s: relative path requires site", u)
+			}
+			u = strings.TrimRight(site, "/") + u
+		}
+		// Статистика собирается по шаблону, а не по каждому адресу
+		cfg.URLs = []string{normalizeSite(u)}
+		return []target{{url: cfg.URLs[0], method: method}}, nil
+	}
+
+	if len(cfg.WeightedURLs) > 0 {
+		targets := make([]target, len(cfg.WeightedURLs))
+		cfg.URLs = make([]string, len(cfg.WeightedURLs))
+		for i, e := range cfg.WeightedURLs {
+			if e.Weight <= 0 {
+				return nil, fmt.Errorf("weighted url %s: weight must be positive", e.URL)
+			}
+			m := method
+			if e.Method != "" {
+				var err error
+				if m, err = normalizeMethod(e.Method); err != nil {
+					return nil, fmt.Errorf("weighted url %s: %w", e.URL, err)
+				}
+			}
+			u := e.URL
+			if strings.HasPrefix(u, "/") {
+				// Путь из журнала доступа, относительно site
+				if site == "" {
+					return nil, fmt.Errorf("weighted url %s: relative path requires site", e.URL)
+				}
+				u = strings.TrimRight(site, "/") + u
+			}
+			u = normalizeSite(u)
+			targets[i] = target{url: u, method: m, body: e.Body, headers: e.Headers, weight: e.Weight}
+			cfg.URLs[i] = u
+		}
+		return targets, nil
+	}
+
+	if len(cfg.URLs) == 0 {
+		return []target{{url: site, method: method}}, nil
+	}
+	targets := make([]target, len(cfg.URLs))
+	for i, u := range cfg.URLs {
+		cfg.URLs[i] = normalizeSite(u)
+		targets[i] = target{url: cfg.URLs[i], method: method}
+	}
+	return targets, nil
+}
+
+// Возвращает функцию выбора индекса цели: по весам, если они заданы,
+// иначе по кругу через атомарный счетчик
+func newPicker(targets []target) func() int {
+	if targets[0].weight > 0 {
+		weights := make([]int, len(targets))
+		for i, t := range targets {
+			weights[i] = t.weight
+		}
+		return newAliasTable(weights).sample
+	}
+	var next atomic.Uint64
+	n := uint64(len(targets))
+	return func() int {
+		return int((next.Add(1) - 1) % n)
+	}
+}
+
+// Таблица псевдонимов (метод Vose) для выбора по весам за O(1)
+type aliasTable struct {
+	prob  []float64
+	alias []int
+}
+
+func newAliasTable(weights []int) *aliasTable {
+	n := len(weights)
+	total := 0
+	for _, w := range weights {
+		total += w
+	}
+
+	t := &aliasTable{prob: make([]float64, n), alias: make([]int, n)}
+	scaled := make([]float64, n)
+	var small, large []int
+	for i, w := range weights {
+		scaled[i] = float64(w) * float64(n) / float64(total)
+		if scaled[i] < 1 {
+			small = append(small, i)
+		} else {
+			large = append(large, i)
+		}
+	}
+	for len(small) > 0 && len(large) > 0 {
+		s, l := small[len(small)-1], large[len(large)-1]
+		small = small[:len(small)-1]
+		t.prob[s] = scaled[s]
+		t.alias[s] = l
+		scaled[l] -= 1 - scaled[s]
+		if scaled[l] < 1 {
+			large = large[:len(large)-1]
+			small = append(small, l)
+		}
+	}
+	// Остатки из-за погрешности округления считаются полными корзинами
+	for _, i := range append(small, large...) {
+		t.prob[i] = 1
+	}
+	return t
+}
+
+func (t *aliasTable) sample() int {
+	i := int(rand.Int63n(int64(len(t.prob))))
+	if float64(rand.Int63())/(1<<63) < t.prob[i] {
+		return i
+	}
+	return t.alias[i]
+}
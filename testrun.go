@@ -0,0 +1,148 @@
+package gohttptest
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+)
+
+// Отметка о событии во время теста, например "deployed new version"
+type Annotation struct {
+	Label string `json:"label"`
+	// Время от начала теста, включая прогрев
+	At time.Duration `json:"at"`
+}
+
+func (a Annotation) MarshalJSON() ([]byte, error) {
+	return marshalWithDurations(a)
+}
+
+// Тест, запущенный через Start
+type TestRun struct {
+	cancel context.CancelFunc
+	done   chan struct{}
+	result BenchmarkResult
+	err    error
+
+	mu          sync.Mutex
+	start       time.Time
+	annotations []Annotation
+
+	// Защищает st: результаты добавляет цикл теста, LiveStats читает
+	statsMu sync.Mutex
+	st      *stats
+}
+
+// Запускает тест в фоне и сразу возвращается. Конкурентность и количество
+// запросов задаются WithConcurrency и WithRequestCount или WithDuration.
+// Ошибка возвращается только для неверных опций, ошибки теста - Err
+func Start(ctx context.Context, site string, opts ...Option) (*TestRun, error) {
+	cfg, err := NewConfig(opts...)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.Context != nil {
+		ctx = cfg.Context
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	cfg.Context = ctx
+
+	r := &TestRun{cancel: cancel, done: make(chan struct{})}
+	cfg.testRun = r
+	go func() {
+		defer close(r.done)
+		defer cancel()
+		r.result, r.err = run(*cfg, site)
+	}()
+	return r, nil
+}
+
+// Закрывается по завершении теста
+func (r *TestRun) Done() <-chan struct{} {
+	return r.done
+}
+
+// Итоговый результат, ждет завершения теста
+func (r *TestRun) Result() BenchmarkResult {
+	<-r.done
+	return r.result
+}
+
+// Ошибка теста (нарушение SLA, неверный адрес), ждет завершения теста
+func (r *TestRun) Err() error {
+	<-r.done
+	return r.err
+}
+
+// Статистика на текущий момент, не ждет завершения. До начала запросов -
+// пустой результат, после завершения - итоговый
+func (r *TestRun) LiveStats() BenchmarkResult {
+	select {
+	case <-r.done:
+		return r.result
+	default:
+	}
+	r.statsMu.Lock()
+	defer r.statsMu.Unlock()
+	if r.st == nil {
+		return BenchmarkResult{}
+	}
+	r.mu.Lock()
+	elapsed := time.Since(r.start)
+	r.mu.Unlock()
+	bench := r.st.finish(elapsed)
+	r.annotate(&bench)
+	return bench
+}
+
+// Останавливает тест, как отмена контекста. Result вернет статистику
+// выполненных запросов
+func (r *TestRun) Stop() {
+	r.cancel()
+}
+
+// Отмечает событие текущим временем теста. Отметка попадает в
+// BenchmarkResult.Annotations и в секунду TimeSeries, на которую пришлась.
+// Отметки до начала запросов получают At = 0
+func (r *TestRun) Annotate(label string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var at time.Duration
+	if !r.start.IsZero() {
+		at = time.Since(r.start)
+	}
+	r.annotations = append(r.annotations, Annotation{Label: label, At: at})
+}
+
+// Запоминает начало теста и накопитель статистики для LiveStats
+func (r *TestRun) begin(start time.Time, st *stats) {
+	r.mu.Lock()
+	r.start = start
+	r.mu.Unlock()
+	r.statsMu.Lock()
+	r.st = st
+	r.statsMu.Unlock()
+}
+
+// Добавляет результат запроса под блокировкой, чтобы LiveStats не читал
+// статистику во время изменения
+func (r *TestRun) add(res result) {
+	r.statsMu.Lock()
+	r.st.add(res)
+	r.statsMu.Unlock()
+}
+
+// Добавляет отметки в результат и в его секунды
+func (r *TestRun) annotate(bench *BenchmarkResult) {
+	r.mu.Lock()
+	bench.Annotations = slices.Clone(r.annotations)
+	r.mu.Unlock()
+	for _, a := range bench.Annotations {
+		sec := int(a.At / time.Second)
+		i := slices.IndexFunc(bench.TimeSeries, func(p TimePoint) bool { return p.Second == sec })
+		if i >= 0 {
+			bench.TimeSeries[i].Annotations = append(bench.TimeSeries[i].Annotations, a.Label)
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package gohttptest
+
+import (
+	"crypto/tls"
+	"net/http/httptrace"
+	"time"
+)
+
+// Тайминги отдельных этапов одного запроса через httptrace
+type requestTrace struct {
+	start    time.Time
+	ttfb     time.Duration
+	dnsStart time.Time
+	dns      time.Duration
+	tlsStart time.Time
+	tls      time.Duration
+}
+
+func (t *requestTrace) clientTrace() *httptrace.ClientTrace {
+	return &httptrace.ClientTrace{
+		DNSStart: func(httptrace.DNSStartInfo) {
+			t.dnsStart = time.Now()
+		},
+		DNSDone: func(httptrace.DNSDoneInfo) {
+			if !t.dnsStart.IsZero() {
+				t.dns += time.Since(t.dnsStart)
+			}
+		},
+		GotFirstResponseByte: func() {
+			t.ttfb = time.Since(t.start)
+		},
+		TLSHandshakeStart: func() {
+			t.tlsStart = time.Now()
+		},
+		TLSHandshakeDone: func(tls.ConnectionState, error) {
+			if !t.tlsStart.IsZero() {
+				t.tls += time.Since(t.tlsStart)
+			}
+		},
+	}
+}
+
+// Минимум, максимум и среднее одного этапа запроса по всем запросам,
+// где этот этап был (например, DNS только при новом соединении)
+type phaseStats struct {
+	count int
+	total time.Duration
+	min   time.Duration
+	max   time.Duration
+}
+
+func (p *phaseStats) add(d time.Duration) {
+	if d <= 0 {
+		return
+	}
+	if p.count == 0 || d < p.min {
+		p.min = d
+	}
+	p.max = max(p.max, d)
+	p.total += d
+	p.count++
+}
+
+func (p *phaseStats) avg() time.Duration {
+	if p.count == 0 {
+		return 0
+	}
+	return p.total / time.Duration(p.count)
+}
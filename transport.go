@@ -0,0 +1,189 @@
+package gohttptest
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/net/http2"
+	"golang.org/x/net/proxy"
+)
+
+// Создает транспорт для всех воркеров теста, conns считает новые TCP соединения
+func newTransport(cfg *Config, conns *atomic.Int64) (*http.Transport, error) {
+	t := http.DefaultTransport.(*http.Transport).Clone()
+
+	dialer := &net.Dialer{
+		Timeout:   30 * time.Second,
+		KeepAlive: 30 * time.Second,
+	}
+	if cfg.DialTimeout > 0 {
+		dialer.Timeout = cfg.DialTimeout
+	}
+	dialContext := dialer.DialContext
+	if cfg.SOCKS5Addr != "" {
+		if cfg.proxyURL != nil {
+			return nil, errors.New("WithProxy and WithSOCKS5Proxy can not be used together")
+		}
+		var auth *proxy.Auth
+		if cfg.SOCKS5User != "" || cfg.SOCKS5Password != "" {
+			auth = &proxy.Auth{User: cfg.SOCKS5User, Password: cfg.SOCKS5Password}
+		}
+		socks, err := proxy.SOCKS5("tcp", cfg.SOCKS5Addr, auth, dialer)
+		if err != nil {
+			return nil, fmt.Errorf("socks5 proxy: %w", err)
+		}
+		// Dialer из proxy.SOCKS5 всегда умеет DialContext
+		dialContext = socks.(proxy.ContextDialer).DialContext
+	}
+	family := ipFamily(cfg)
+	dial := func(ctx context.Context, network, addr string) (net.Conn, error) {
+		if family != "" && network == "tcp" {
+			network = "tcp" + family
+		}
+		conn, err := dialContext(ctx, network, addr)
+		if err == nil {
+			conns.Add(1)
+		}
+		return conn, err
+	}
+	t.DialContext = dial
+	t.DisableKeepAlives = cfg.DisableKeepAlive
+	if cfg.AcceptEncoding != "" {
+		t.DisableCompression = true
+	}
+	if cfg.MaxIdleConns > 0 {
+		t.MaxIdleConns = cfg.MaxIdleConns
+	}
+	if cfg.MaxIdleConnsPerHost > 0 {
+		t.MaxIdleConnsPerHost = cfg.MaxIdleConnsPerHost
+	} else if cfg.Prewarm > t.MaxIdleConnsPerHost {
+		// Иначе транспорт закроет лишние прогретые соединения
+		t.MaxIdleConnsPerHost = cfg.Prewarm
+	}
+	if cfg.MaxConnsPerHost > 0 {
+		t.MaxConnsPerHost = cfg.MaxConnsPerHost
+	}
+	if cfg.ResponseTimeout > 0 {
+		t.ResponseHeaderTimeout = cfg.ResponseTimeout
+	}
+	switch {
+	case cfg.SOCKS5Addr != "":
+		// Соединения идут через SOCKS5 в dial, HTTP прокси не нужен
+		t.Proxy = nil
+	case cfg.proxyURL != nil:
+		t.Proxy = http.ProxyURL(cfg.proxyURL)
+	default:
+		t.Proxy = http.ProxyFromEnvironment
+	}
+	if tc := tlsConfig(cfg); tc != nil {
+		t.TLSClientConfig = tc
+	}
+
+	if cfg.HTTP2 != nil {
+		if *cfg.HTTP2 {
+			if err := forceHTTP2(t, dial); err != nil {
+				return nil, err
+			}
+		} else {
+			// Пустой TLSNextProto отключает HTTP/2 через ALPN
+			t.ForceAttemptHTTP2 = false
+			t.TLSNextProto = map[string]func(string, *tls.Conn) http.RoundTripper{}
+		}
+	}
+
+	return t, nil
+}
+
+// "4" или "6" для WithIPv4Only/WithIPv6Only, пусто - любое семейство
+func ipFamily(cfg *Config) string {
+	switch {
+	case cfg.IPv4Only:
+		return "4"
+	case cfg.IPv6Only:
+		return "6"
+	}
+	return ""
+}
+
+// Проверяет, что у каждого хоста есть адрес нужного семейства
+func checkIPFamily(ctx context.Context, cfg *Config, targets []target) error {
+	family := ipFamily(cfg)
+	if family == "" {
+		return nil
+	}
+	checked := make(map[string]bool)
+	for _, t := range targets {
+		u, err := url.Parse(t.url)
+		if err != nil {
+			return err
+		}
+		host := u.Hostname()
+		if checked[host] {
+			continue
+		}
+		checked[host] = true
+		if _, err := net.DefaultResolver.LookupIP(ctx, "ip"+family, host); err != nil {
+			return fmt.Errorf("%s has no IPv%s address: %w", host, family, err)
+		}
+	}
+	return nil
+}
+
+// Включает HTTP/2: для https через ALPN, для http - h2c без TLS
+func forceHTTP2(t *http.Transport, dial func(ctx context.Context, network, addr string) (net.Conn, error)) error {
+	if _, err := http2.ConfigureTransports(t); err != nil {
+		return fmt.Errorf("configure http2: %w", err)
+	}
+
+	h2c := &http2.Transport{
+		AllowHTTP: true,
+		DialTLSContext: func(ctx context.Context, network, addr string, _ *tls.Config) (net.Conn, error) {
+			return dial(ctx, network, addr)
+		},
+	}
+	t.RegisterProtocol("http", h2c)
+	return nil
+}
+
+// Общий TLS конфиг для транспорта, nil если TLS настройки не заданы
+func tlsConfig(cfg *Config) *tls.Config {
+	if !cfg.TLSSkipVerify && cfg.rootCAs == nil && len(cfg.clientCerts) == 0 && cfg.SNI == "" {
+		return nil
+	}
+	return &tls.Config{
+		InsecureSkipVerify: cfg.TLSSkipVerify,
+		RootCAs:            cfg.rootCAs,
+		Certificates:       cfg.clientCerts,
+		ServerName:         cfg.SNI,
+	}
+}
+
+// Вид таймаута, которым закончился запрос
+type timeoutKind int
+
+const (
+	noTimeout timeoutKind = iota
+	// Не удалось установить соединение
+	dialTimeout
+	// Соединение есть, но ответ не получен вовремя
+	responseTimeout
+)
+
+func classifyTimeout(err error) timeoutKind {
+	var opErr *net.OpError
+	if errors.As(err, &opErr) && opErr.Op == "dial" && opErr.Timeout() {
+		return dialTimeout
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return responseTimeout
+	}
+	return noTimeout
+}
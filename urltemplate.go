@@ -0,0 +1,60 @@
+package gohttptest
+
+import (
+	"encoding/csv"
+	"fmt"
+	"net/url"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// Подстановки вида {column_name}
+var templateVarRe = regexp.MustCompile(`\{([A-Za-z0-9_.-]+)\}`)
+
+// Шаблон адреса для WithURLTemplate и строки данных из CSV файла
+type urlTemplate struct {
+	columns []string
+	rows    [][]string
+}
+
+// Читает CSV файл с заголовком и проверяет, что для каждой подстановки
+// шаблона есть колонка
+func readURLTemplate(template, dataFile string) (*urlTemplate, error) {
+	f, err := os.Open(dataFile)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	records, err := csv.NewReader(f).ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("parse %s: %w", dataFile, err)
+	}
+	if len(records) < 2 {
+		return nil, fmt.Errorf("%s must contain a header row and at least one data row", dataFile)
+	}
+	t := &urlTemplate{columns: records[0], rows: records[1:]}
+
+	vars := templateVarRe.FindAllStringSubmatch(template, -1)
+	if len(vars) == 0 {
+		return nil, fmt.Errorf("template %s has no {column} placeholders", template)
+	}
+	for _, v := range vars {
+		if !slices.Contains(t.columns, v[1]) {
+			return nil, fmt.Errorf("template placeholder {%s} has no column in %s", v[1], dataFile)
+		}
+	}
+	return t, nil
+}
+
+// Подставляет в адрес значения строки row (по кругу), значения
+// экранируются как сегменты пути
+func (t *urlTemplate) expand(u string, row uint64) string {
+	values := t.rows[row%uint64(len(t.rows))]
+	return templateVarRe.ReplaceAllStringFunc(u, func(m string) string {
+		i := slices.Index(t.columns, strings.Trim(m, "{}"))
+		return url.PathEscape(values[i])
+	})
+}
@@ -0,0 +1,27 @@
+package gohttptest
+
+import (
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"slices"
+	"time"
+)
+
+// Печатает строку подробного режима о завершенном запросе и под ней
+// отправленные заголовки со скрытыми секретами
+func logRequest(w io.Writer, res result, t target) {
+	prefix := fmt.Sprintf("[worker-%d] %s %s ->", res.Worker, t.method, displayURL(t.url))
+	if res.Error != nil {
+		fmt.Fprintf(w, "%s error in %v: %v\n", prefix, res.Duration.Round(time.Microsecond), res.Error)
+	} else {
+		fmt.Fprintf(w, "%s %d %s in %v (%d B)\n", prefix, res.StatusCode, http.StatusText(res.StatusCode),
+			res.Duration.Round(time.Microsecond), res.Bytes)
+	}
+	for _, name := range slices.Sorted(maps.Keys(res.Headers)) {
+		for _, v := range res.Headers[name] {
+			fmt.Fprintf(w, "  %s: %s\n", name, v)
+		}
+	}
+}
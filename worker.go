@@ -0,0 +1,481 @@
+package gohttptest
+
+import (
+	"bytes"
+	"context"
+	crand "crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"math"
+	"math/rand"
+	"net/http"
+	"net/http/cookiejar"
+	"net/http/httptrace"
+	"net/url"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Размер прочитанного тела ответа не совпал с заголовком Content-Length
+var ErrContentLengthMismatch = errors.New("content length mismatch")
+
+// Воркер - виртуальный пользователь со своим http.Client
+type worker struct {
+	id        int
+	cfg       *Config
+	client    *http.Client
+	userAgent string
+	// Имена проверяемых заголовков ответа в порядке сортировки
+	required []string
+	// nil, если трассировка не включена
+	tracer trace.Tracer
+	// Свой генератор у каждого воркера, глобальный общий для всех горутин
+	rnd *rand.Rand
+	// Состояние между запросами, передается в хуки
+	state WorkerContext
+}
+
+// transport не используется, если задан cfg.Client
+func newWorker(id int, cfg *Config, transport *http.Transport) *worker {
+	w := &worker{
+		id:        id,
+		cfg:       cfg,
+		userAgent: cfg.UserAgent,
+		rnd:       rand.New(rand.NewSource(time.Now().UnixNano() + int64(id))),
+		state:     NewWorkerContext(),
+	}
+	if cfg.Client != nil {
+		// Копия клиента, чтобы у каждого воркера мог быть свой jar
+		c := *cfg.Client
+		w.client = &c
+	} else {
+		w.client = &http.Client{
+			Transport: transport,
+			Timeout:   cfg.requestTimeout(),
+		}
+	}
+	w.client.CheckRedirect = w.checkRedirect(w.client.CheckRedirect)
+	if cfg.CookieJar && w.client.Jar == nil {
+		// У каждого воркера свой jar: воркер - отдельный пользователь
+		w.client.Jar, _ = cookiejar.New(nil)
+	}
+	w.required = slices.Sorted(maps.Keys(cfg.RequiredHeaders))
+	if cfg.TracerProvider != nil {
+		w.tracer = cfg.TracerProvider.Tracer("github.com/batman565/gohttptest")
+	}
+	if len(cfg.UserAgents) > 0 {
+		w.userAgent = cfg.UserAgents[id%len(cfg.UserAgents)]
+	}
+	return w
+}
+
+// Выполняет один запрос и возвращает его результат
+func (w *worker) do(ctx context.Context, t target) result {
+	cfg := w.cfg
+	method, site := t.method, t.url
+	reqStart := time.Now()
+
+	req, bodyBytes, err := w.newRequest(ctx, t)
+	if err != nil {
+		return result{
+			Start:      reqStart,
+			StatusCode: 0,
+			Duration:   time.Since(reqStart),
+			Error:      err,
+		}
+	}
+	if req.Body != nil {
+		// Транспорт закрывает тело сам, но не при ошибке до отправки
+		defer req.Body.Close()
+	}
+	var requestID string
+	if cfg.RequestIDHeader != "" {
+		requestID = req.Header.Get(cfg.RequestIDHeader)
+	}
+
+	var span trace.Span
+	if w.tracer != nil {
+		var spanCtx context.Context
+		spanCtx, span = w.tracer.Start(req.Context(), "HTTP "+method,
+			trace.WithSpanKind(trace.SpanKindClient),
+			trace.WithAttributes(
+				attribute.String("http.method", method),
+				attribute.String("http.url", displayURL(site)),
+			))
+		req = req.WithContext(spanCtx)
+		propagation.TraceContext{}.Inject(spanCtx, propagation.HeaderCarrier(req.Header))
+	}
+
+	var sent http.Header
+	if cfg.Verbose {
+		sent = redactHeaders(req.Header, cfg)
+	}
+
+	trace := &requestTrace{start: reqStart}
+	var redirects int
+	reqCtx := context.WithValue(req.Context(), redirectsKey{}, &redirects)
+	req = req.WithContext(httptrace.WithClientTrace(reqCtx, trace.clientTrace()))
+
+	resp, err := w.client.Do(req)
+	duration := time.Since(reqStart)
+
+	if err != nil {
+		res := result{
+			Start:        reqStart,
+			StatusCode:   0,
+			Duration:     duration,
+			RequestBytes: bodyBytes,
+			Error:        err,
+			Timeout:      classifyTimeout(err),
+			DNSDuration:  trace.dns,
+			TLSHandshake: trace.tls,
+			Headers:      sent,
+			RequestID:    requestID,
+		}
+		w.callHook(req, nil, &res)
+		endSpan(span, res)
+		return res
+	}
+
+	respBytes, readErr := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	// Полное время, включая чтение тела ответа
+	duration = time.Since(reqStart)
+
+	// Для проверок и хука используется распакованное тело
+	content := respBytes
+	var compressed, decompressed int64
+	var respErr error
+	if readErr != nil {
+		respErr = fmt.Errorf("read response body: %w", readErr)
+	}
+	if respErr == nil && resp.Header.Get("Content-Encoding") == "gzip" && len(respBytes) > 0 {
+		if content, err = gunzipBytes(respBytes); err != nil {
+			respErr = fmt.Errorf("decompress response: %w", err)
+		}
+		compressed, decompressed = int64(len(respBytes)), int64(len(content))
+	}
+	if respErr == nil && cfg.ValidateContentLength && resp.ContentLength >= 0 && method != http.MethodHead &&
+		resp.ContentLength != int64(len(respBytes)) {
+		respErr = fmt.Errorf("%w: declared %d, read %d", ErrContentLengthMismatch, resp.ContentLength, len(respBytes))
+	}
+
+	var reason failureReason
+	failed := w.checkHeaders(resp.Header)
+	if len(failed) > 0 {
+		reason = headerFailure
+		if respErr == nil {
+			respErr = fmt.Errorf("response header check failed: %s", strings.Join(failed, ", "))
+		}
+	}
+	if cfg.BodyRegex != nil && method != http.MethodHead && !cfg.BodyRegex.Match(content) {
+		reason = bodyFailure
+		if respErr == nil {
+			respErr = fmt.Errorf("response body does not match %q", cfg.BodyRegex)
+		}
+	}
+
+	res := result{
+		Start:        reqStart,
+		StatusCode:   resp.StatusCode,
+		Duration:     duration,
+		Bytes:        int64(len(respBytes)),
+		RequestBytes: bodyBytes,
+		Error:        respErr,
+		Proto:        resp.Proto,
+		Via:          resp.Header.Get("Via") != "",
+		DNSDuration:  trace.dns,
+		TLSHandshake: trace.tls,
+		TTFB:         trace.ttfb,
+		Redirects:    redirects,
+		Headers:      sent,
+		RequestID:    requestID,
+
+		CompressedBytes:   compressed,
+		DecompressedBytes: decompressed,
+
+		FailureReason: reason,
+		FailedHeaders: failed,
+	}
+
+	// Настоящее тело уже прочитано и закрыто, хук получает копию из памяти
+	resp.Body = io.NopCloser(bytes.NewReader(content))
+	w.callHook(req, resp, &res)
+	endSpan(span, res)
+	return res
+}
+
+// Завершает span запроса, span может быть nil
+func endSpan(span trace.Span, res result) {
+	if span == nil {
+		return
+	}
+	if res.StatusCode != 0 {
+		span.SetAttributes(
+			attribute.Int("http.status_code", res.StatusCode),
+			attribute.Int64("http.response_content_length", res.Bytes),
+		)
+	}
+	if res.Error != nil {
+		span.RecordError(res.Error)
+		span.SetStatus(codes.Error, res.Error.Error())
+	} else if res.StatusCode >= 400 {
+		span.SetStatus(codes.Error, http.StatusText(res.StatusCode))
+	}
+	span.End()
+}
+
+// Возвращает заголовки ответа, не прошедшие проверку WithRequiredHeader
+func (w *worker) checkHeaders(h http.Header) []string {
+	var failed []string
+	for _, name := range w.required {
+		want := w.cfg.RequiredHeaders[name]
+		values, ok := h[name]
+		if !ok || (want != "" && !slices.Contains(values, want)) {
+			failed = append(failed, name)
+		}
+	}
+	return failed
+}
+
+// Ключ контекста запроса со счетчиком выполненных редиректов
+type redirectsKey struct{}
+
+// Политика редиректов клиента: считает переходы и ограничивает их
+// количеством MaxRedirects. Если у клиента из WithClient своя политика,
+// ограничение берется из нее
+func (w *worker) checkRedirect(next func(*http.Request, []*http.Request) error) func(*http.Request, []*http.Request) error {
+	return func(req *http.Request, via []*http.Request) error {
+		var err error
+		switch {
+		case next != nil:
+			err = next(req, via)
+		case w.cfg.MaxRedirects == 0:
+			return http.ErrUseLastResponse
+		case len(via) >= w.cfg.MaxRedirects:
+			err = fmt.Errorf("stopped after %d redirects", w.cfg.MaxRedirects)
+		}
+		if n, ok := req.Context().Value(redirectsKey{}).(*int); ok && err == nil {
+			*n = len(via)
+		}
+		return err
+	}
+}
+
+// Выполняет запрос с повторами по WithRetry
+func (w *worker) doRetry(ctx context.Context, t target) result {
+	res := w.do(ctx, t)
+	for retries := 1; retries <= w.cfg.MaxRetries && retryable(res) && ctx.Err() == nil; retries++ {
+		select {
+		case <-ctx.Done():
+			return res
+		case <-time.After(w.cfg.RetryBackoff):
+		}
+		res = w.do(ctx, t)
+		res.Retries = retries
+	}
+	return res
+}
+
+// Пауза между запросами по WithThinkTime или WithPoissonThinkTime. false, если тест отменен
+func (w *worker) think(ctx context.Context) bool {
+	d := w.cfg.ThinkTimeMin
+	if spread := w.cfg.ThinkTimeMax - w.cfg.ThinkTimeMin; spread > 0 {
+		d += time.Duration(w.rnd.Int63n(int64(spread) + 1))
+	}
+	if mean := w.cfg.PoissonThinkTime; mean > 0 {
+		// 1-Float64 в (0, 1], логарифм нуля не получится
+		d = time.Duration(-float64(mean) * math.Log(1-w.rnd.Float64()))
+	}
+	if d <= 0 {
+		return true
+	}
+	select {
+	case <-ctx.Done():
+		return false
+	case <-time.After(d):
+		return true
+	}
+}
+
+// Повторяются сетевые ошибки и ошибки сервера
+func retryable(res result) bool {
+	return (res.StatusCode == 0 && res.Error != nil) || res.StatusCode >= 500
+}
+
+// Собирает запрос к t со всеми заголовками, телом и авторизацией,
+// вторым значением возвращает размер тела
+func (w *worker) newRequest(ctx context.Context, t target) (*http.Request, int64, error) {
+	cfg := w.cfg
+
+	var (
+		body      io.Reader
+		bodyBytes int64
+		streamed  bool
+		gzipped   bool
+	)
+	switch {
+	case t.body != nil:
+		body, bodyBytes = bytes.NewReader(t.body), int64(len(t.body))
+	case cfg.Body != nil:
+		body, bodyBytes = bytes.NewReader(cfg.Body), int64(len(cfg.Body))
+		gzipped = cfg.CompressBody
+	case cfg.BodyFile != "":
+		f, size, err := openBodyFile(cfg.BodyFile)
+		if err != nil {
+			return nil, 0, err
+		}
+		body, bodyBytes, streamed = f, size, true
+	case cfg.bodySchema != nil:
+		data, err := cfg.bodySchema.generate(w.rnd)
+		if err != nil {
+			return nil, 0, fmt.Errorf("generate body: %w", err)
+		}
+		body, bodyBytes = bytes.NewReader(data), int64(len(data))
+	}
+
+	u := t.url
+	if cfg.urlTemplate != nil {
+		u = cfg.urlTemplate.expand(u, cfg.templateRow.Add(1)-1)
+	}
+	req, err := http.NewRequestWithContext(ctx, t.method, u, body)
+	if err != nil {
+		if streamed {
+			body.(*os.File).Close()
+		}
+		return nil, 0, err
+	}
+	if streamed {
+		req.ContentLength = bodyBytes
+	}
+	if len(cfg.RandomQueryParams) > 0 {
+		q := make(url.Values, len(cfg.RandomQueryParams))
+		for name, values := range cfg.RandomQueryParams {
+			q.Set(name, values[w.rnd.Intn(len(values))])
+		}
+		if req.URL.RawQuery == "" {
+			req.URL.RawQuery = q.Encode()
+		} else {
+			req.URL.RawQuery += "&" + q.Encode()
+		}
+	}
+	if cfg.cacheBust != nil {
+		cb := "_cb=" + strconv.FormatUint(cfg.cacheBust.Add(1), 16)
+		if req.URL.RawQuery == "" {
+			req.URL.RawQuery = cb
+		} else {
+			req.URL.RawQuery += "&" + cb
+		}
+	}
+
+	if w.userAgent != "" {
+		req.Header.Set("User-Agent", w.userAgent)
+	}
+	if cfg.ContentType != "" {
+		req.Header.Set("Content-Type", cfg.ContentType)
+	}
+	if gzipped {
+		req.Header.Set("Content-Encoding", "gzip")
+	}
+	if cfg.AcceptEncoding != "" {
+		req.Header.Set("Accept-Encoding", cfg.AcceptEncoding)
+	}
+	if cfg.RequestIDHeader != "" {
+		req.Header.Set(cfg.RequestIDHeader, newUUID())
+	}
+	for k, v := range cfg.Headers {
+		req.Header.Set(k, v)
+	}
+	for k, v := range t.headers {
+		req.Header.Set(k, v)
+	}
+	if cfg.HostHeader != "" {
+		req.Host = cfg.HostHeader
+	}
+	if cfg.BasicAuthUser != "" || cfg.BasicAuthPassword != "" {
+		req.SetBasicAuth(cfg.BasicAuthUser, cfg.BasicAuthPassword)
+	}
+	switch {
+	case cfg.jwt != nil:
+		req.Header.Set("Authorization", "Bearer "+cfg.jwt.current())
+	case cfg.BearerToken != "":
+		req.Header.Set("Authorization", "Bearer "+cfg.BearerToken)
+	}
+	if cfg.APIKeyHeader != "" {
+		req.Header.Set(cfg.APIKeyHeader, cfg.APIKey)
+	}
+	for _, c := range cfg.Cookies {
+		req.AddCookie(c)
+	}
+	if p, ok := w.state.(RequestPreparer); ok {
+		p.PrepareRequest(req)
+	}
+
+	return req, bodyBytes, nil
+}
+
+// Вызывает пользовательский хук, паника в хуке превращается в ошибку запроса
+func (w *worker) callHook(req *http.Request, resp *http.Response, res *result) {
+	if w.cfg.RequestHook == nil {
+		return
+	}
+	defer func() {
+		if p := recover(); p != nil {
+			res.Error = fmt.Errorf("request hook panic: %v", p)
+		}
+	}()
+	w.cfg.RequestHook(w.state, req, resp, res.Duration, res.Error)
+}
+
+// Открывает файл тела запроса и возвращает его размер
+func openBodyFile(path string) (*os.File, int64, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, 0, err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, 0, err
+	}
+	return f, info.Size(), nil
+}
+
+// Параллельно выполняет cfg.Prewarm запросов к t, чтобы заполнить пул
+// соединений транспорта. Результаты отбрасываются, хук и трассировка
+// для этих запросов не вызываются
+func prewarm(ctx context.Context, cfg *Config, transport *http.Transport, t target) {
+	pcfg := *cfg
+	pcfg.RequestHook = nil
+	pcfg.TracerProvider = nil
+
+	var wg sync.WaitGroup
+	for i := range cfg.Prewarm {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			newWorker(i, &pcfg, transport).do(ctx, t)
+		}()
+	}
+	wg.Wait()
+}
+
+// Случайный UUID версии 4 (RFC 4122)
+func newUUID() string {
+	var b [16]byte
+	crand.Read(b[:])
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
@@ -0,0 +1,74 @@
+package gohttptest
+
+import (
+	"io"
+	"net/http"
+	"strconv"
+	"testing"
+)
+
+// Сервер, который redirects раз перенаправляет на /1, /2... и затем
+// отвечает 200. redirects < 0 - бесконечная петля
+func redirectServer(redirects int) *MockServer {
+	return NewMockServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n, _ := strconv.Atoi(r.URL.Path[1:])
+		if redirects >= 0 && n >= redirects {
+			return
+		}
+		http.Redirect(w, r, "/"+strconv.Itoa(n+1), http.StatusFound)
+	}))
+}
+
+// По умолчанию запрос к петле проходит столько же шагов, сколько
+// стандартный клиент Go
+func TestMaxRedirectsDefaultMatchesGo(t *testing.T) {
+	goSrv := redirectServer(-1)
+	defer goSrv.Close()
+	if _, err := http.Get(goSrv.URL() + "/0"); err == nil {
+		t.Fatal("http.Get followed an endless redirect loop")
+	}
+
+	srv := redirectServer(-1)
+	defer srv.Close()
+	res, err := Test(srv.URL()+"/0", 1, 1, WithOutput(io.Discard))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := srv.RequestCount(), goSrv.RequestCount(); got != want {
+		t.Errorf("server got %d requests, net/http default makes %d", got, want)
+	}
+	if res.FailedCount != 1 {
+		t.Errorf("FailedCount = %d, want 1", res.FailedCount)
+	}
+}
+
+func TestMaxRedirectsBoundary(t *testing.T) {
+	tests := []struct {
+		name         string
+		max          int
+		redirects    int
+		wantRequests int
+		wantFailed   int
+	}{
+		{"redirects below limit", 3, 2, 3, 0},
+		{"redirects at limit", 3, 3, 3, 1},
+		{"loop", 3, -1, 3, 1},
+		{"disabled", 0, -1, 1, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := redirectServer(tt.redirects)
+			defer srv.Close()
+			res, err := Test(srv.URL()+"/0", 1, 1, WithOutput(io.Discard), WithMaxRedirects(tt.max))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := srv.RequestCount(); got != tt.wantRequests {
+				t.Errorf("server got %d requests, want %d", got, tt.wantRequests)
+			}
+			if res.FailedCount != tt.wantFailed {
+				t.Errorf("FailedCount = %d, want %d", res.FailedCount, tt.wantFailed)
+			}
+		})
+	}
+}
@@ -0,0 +1,34 @@
+package gohttptest
+
+import "net/http"
+
+// Состояние воркера, которое сохраняется между его запросами, например
+// токен сессии после логина. Воркер обращается к нему только из своей
+// горутины, синхронизация не нужна
+type WorkerContext interface {
+	Get(key string) interface{}
+	Set(key string, v interface{})
+}
+
+// Необязательный интерфейс WorkerContext: PrepareRequest вызывается перед
+// отправкой каждого запроса воркера и может добавить в него значения из
+// состояния, например заголовок Authorization с токеном, полученным хуком
+type RequestPreparer interface {
+	PrepareRequest(req *http.Request)
+}
+
+// Состояние воркера по умолчанию - словарь. Подходит для встраивания в свой
+// тип с методом PrepareRequest
+func NewWorkerContext() WorkerContext {
+	return mapWorkerContext{}
+}
+
+type mapWorkerContext map[string]interface{}
+
+func (m mapWorkerContext) Get(key string) interface{} {
+	return m[key]
+}
+
+func (m mapWorkerContext) Set(key string, v interface{}) {
+	m[key] = v
+}